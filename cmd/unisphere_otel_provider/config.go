@@ -0,0 +1,111 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"time"
+	"unisphere_otel_provider/gounity"
+
+	"gopkg.in/yaml.v3"
+)
+
+// clientExtension
+// client's options that the common configuration does not support.
+type clientExtension struct {
+	Endpoint   string                 `yaml:"endpoint"`
+	Collectors map[string]interface{} `yaml:"collectors"`
+	PageSize   int                    `yaml:"page_size"`
+	MaxPages   *int                   `yaml:"max_pages"`
+	Timeout    *time.Duration         `yaml:"timeout"`
+	Retry      *retryConfig           `yaml:"retry"`
+	Breaker    *breakerConfig         `yaml:"circuit_breaker"`
+}
+
+type retryConfig struct {
+	Attempts       *int           `yaml:"attempts"`
+	InitialBackoff *time.Duration `yaml:"initial_backoff"`
+	MaxBackoff     *time.Duration `yaml:"max_backoff"`
+}
+
+type breakerConfig struct {
+	Failures      *int           `yaml:"failures"`
+	ProbeInterval *time.Duration `yaml:"probe_interval"`
+}
+
+// extendedConfiguration
+// read the same config file again, to get provider specific options.
+type extendedConfiguration struct {
+	Clients []*clientExtension `yaml:"clients"`
+
+	byEndpoint map[string]*clientExtension
+}
+
+// loadExtendedConfiguration
+// load extensions of clients, keyed by their endpoint.
+// endpoints must be unique, otherwise extensions cannot be matched to the client.
+func loadExtendedConfiguration(path string) (*extendedConfiguration, error) {
+	cfg := &extendedConfiguration{byEndpoint: make(map[string]*clientExtension)}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return cfg, err
+	}
+	if err = yaml.Unmarshal(content, cfg); err != nil {
+		return cfg, err
+	}
+	for _, client := range cfg.Clients {
+		if client == nil {
+			continue
+		}
+		if _, ok := cfg.byEndpoint[client.Endpoint]; ok {
+			return cfg, fmt.Errorf("duplicate client endpoint %q", client.Endpoint)
+		}
+		cfg.byEndpoint[client.Endpoint] = client
+	}
+	return cfg, nil
+}
+
+// client
+// return extension of the client by its endpoint, empty when it has no extension.
+func (_cfg *extendedConfiguration) client(endpoint string) *clientExtension {
+	if ext, ok := _cfg.byEndpoint[endpoint]; ok {
+		return ext
+	}
+	return &clientExtension{}
+}
+
+// retryPolicy
+// return the retry policy, default values are used for the omitted fields.
+func (_cfg *clientExtension) retryPolicy() gounity.RetryPolicy {
+	policy := gounity.DefaultRetryPolicy
+	if _cfg.Retry == nil {
+		return policy
+	}
+	if _cfg.Retry.Attempts != nil {
+		policy.Attempts = *_cfg.Retry.Attempts
+	}
+	if _cfg.Retry.InitialBackoff != nil {
+		policy.InitialBackoff = *_cfg.Retry.InitialBackoff
+	}
+	if _cfg.Retry.MaxBackoff != nil {
+		policy.MaxBackoff = *_cfg.Retry.MaxBackoff
+	}
+	return policy
+}
+
+// circuitBreaker
+// return failures and probe interval of the circuit breaker,
+// default values are used for the omitted fields.
+func (_cfg *clientExtension) circuitBreaker() (int, time.Duration) {
+	failures := gounity.DefaultBreakerFailures
+	probeInterval := gounity.DefaultBreakerProbeInterval
+	if _cfg.Breaker == nil {
+		return failures, probeInterval
+	}
+	if _cfg.Breaker.Failures != nil {
+		failures = *_cfg.Breaker.Failures
+	}
+	if _cfg.Breaker.ProbeInterval != nil {
+		probeInterval = *_cfg.Breaker.ProbeInterval
+	}
+	return failures, probeInterval
+}
@@ -2,10 +2,14 @@ package main
 
 import (
 	"context"
+	"fmt"
 	"log/slog"
 	"os"
+	"os/signal"
+	"syscall"
 	"unisphere_otel_provider/collectors"
 	"unisphere_otel_provider/gounity"
+	providerUtils "unisphere_otel_provider/utils"
 
 	"github.com/Arinashin3/otel/config"
 
@@ -13,13 +17,17 @@ import (
 	"github.com/alecthomas/kingpin/v2"
 	"github.com/prometheus/common/promslog"
 	promslogflag "github.com/prometheus/common/promslog/flag"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/sdk/resource"
 )
 
 const serviceName = "unisphere_otel_provider"
 
 var (
-	configFile = kingpin.Flag("config.file", "Paths to config file.").Short('c').Default("config.yml").String()
-	logger     *slog.Logger
+	configFile     = kingpin.Flag("config.file", "Paths to config file.").Short('c').Default("config.yml").String()
+	listCollectors = kingpin.Flag("collectors.list", "List collectors with their effective enabled state and exit.").Bool()
+	stateFile      = kingpin.Flag("state.file", "Path to state file to resume event and alert polling across restarts (disabled when empty).").Default("unisphere_otel_provider.state.json").String()
+	logger         *slog.Logger
 )
 
 func main() {
@@ -35,6 +43,41 @@ func main() {
 	cfg := config.NewConfiguration()
 	cfg.LoadFile(*configFile, logger)
 
+	ext, err := loadExtendedConfiguration(*configFile)
+	if err != nil {
+		logger.Error("cannot load client's extended options", "error", err)
+		os.Exit(1)
+	}
+
+	// Check Collector Configurations...
+	checkCollectors := func(collectorsCfg map[string]interface{}, endpoint string) {
+		for k := range collectorsCfg {
+			if !collectors.HasModule(k) {
+				logger.Warn("unknown collector in config file", "collector", k, "client", endpoint)
+			}
+		}
+	}
+	checkCollectors(cfg.Collectors, "")
+	for _, client := range cfg.Clients {
+		checkCollectors(ext.client(*client.Endpoint).Collectors, *client.Endpoint)
+	}
+
+	if *listCollectors {
+		printCollectors := func(title string, modules map[string]collectors.Module) {
+			fmt.Println(title)
+			for _, k := range collectors.ModuleNames() {
+				fmt.Printf("  %-20s enabled=%t\n", k, modules[k].IsEnabled())
+			}
+		}
+		if len(cfg.Clients) == 0 {
+			printCollectors("global", collectors.NewModules(cfg.Collectors))
+		}
+		for _, client := range cfg.Clients {
+			printCollectors(*client.Endpoint, collectors.NewModules(cfg.Collectors, ext.client(*client.Endpoint).Collectors))
+		}
+		os.Exit(0)
+	}
+
 	var ctx = context.Background()
 	mps := cfg.GenerateMeterProviders(ctx, serviceName)
 	lps := cfg.GenerateLoggerProviders(ctx, serviceName)
@@ -44,6 +87,15 @@ func main() {
 		os.Exit(1)
 	}
 
+	// Load State of log modules...
+	var state *collectors.StateFile
+	if *stateFile != "" {
+		state, err = collectors.LoadStateFile(*stateFile)
+		if err != nil {
+			logger.Warn("cannot load state file, start without state", "file", *stateFile, "error", err)
+		}
+	}
+
 	trInsecure := utils.NewTransport(true)
 	trSecure := utils.NewTransport(false)
 
@@ -52,6 +104,28 @@ func main() {
 	for _, client := range cfg.Clients {
 		col := collectors.NewCollector(ctx, client.Labels, *client.Interval)
 		col.Instance = *client.Endpoint
+		col.Modules = collectors.NewModules(cfg.Collectors, ext.client(*client.Endpoint).Collectors)
+		col.State = state
+
+		// Exporter for data points with their own timestamps (e.g. metricHistory)
+		// the resource is the same as the MeterProvider's (merged with the environment)
+		var attrs []attribute.KeyValue
+		for k, v := range client.Labels {
+			attrs = append(attrs, attribute.String(k, v))
+		}
+		attrs = append(attrs, attribute.String("service.name", serviceName))
+		col.Resource, err = resource.Merge(resource.Environment(), resource.NewSchemaless(attrs...))
+		if err != nil {
+			logger.Warn("cannot merge resource with the environment", "client", *client.Endpoint, "error", err)
+		}
+		if srv := cfg.Server.Metrics; srv.Enabled {
+			exp, err := providerUtils.NewMetricExporter(ctx, *srv.Mode, *srv.Endpoint+*srv.Api_path, *srv.Insecure)
+			if err != nil || exp == nil || *exp == nil {
+				logger.Warn("cannot create metric exporter", "client", *client.Endpoint, "error", err)
+			} else {
+				col.MetricExporter = *exp
+			}
+		}
 		col.MeterProvider = mps[*client.Endpoint]
 		col.LoggerProvider = lps[*client.Endpoint]
 
@@ -63,19 +137,33 @@ func main() {
 		case false:
 			col.Client = gounity.NewUnisphereClient(*client.Endpoint, basicAuth, trSecure)
 		}
+		maxPages := gounity.DefaultMaxPages
+		if ext.client(*client.Endpoint).MaxPages != nil {
+			maxPages = *ext.client(*client.Endpoint).MaxPages
+		}
+		col.Client.SetLogger(logger)
+		col.Client.SetPagination(ext.client(*client.Endpoint).PageSize, maxPages)
+		if ext.client(*client.Endpoint).Timeout != nil {
+			col.Client.SetTimeout(*ext.client(*client.Endpoint).Timeout)
+		}
+		col.Client.SetRetryPolicy(ext.client(*client.Endpoint).retryPolicy())
+		col.Client.SetCircuitBreaker(ext.client(*client.Endpoint).circuitBreaker())
 		cols = append(cols, col)
 
 	}
 
-	// Set Collector Configurations...
-	for k, v := range cfg.Collectors {
-		collectors.Modules[k].SetConfig(v)
-	}
-
 	// Run Collectors...
 	for _, c := range cols {
 		go c.Start(logger)
 	}
-	select {}
+
+	// Wait Signal & Shutdown Collectors...
+	sigCtx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	<-sigCtx.Done()
+	logger.Info("shutting down")
+	for _, c := range cols {
+		c.Shutdown(logger)
+	}
 
 }
@@ -2,37 +2,111 @@ package collectors
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"log/slog"
+	"strconv"
+	"sync"
+	"sync/atomic"
 	"time"
+	"unisphere_otel_provider/gounity"
 	"unisphere_otel_provider/gounity/api"
 	"unisphere_otel_provider/utils/enum"
 
 	"github.com/tidwall/gjson"
+	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/log"
+	"go.opentelemetry.io/otel/metric"
 )
 
 func init() {
 	key := "alert"
-	registerModule(key, NewAlert())
+	registerModule(key, func() Module { return NewAlert() })
 
 }
 
+// Transitions of an alert, emitted with the `alert.transition` attribute.
+const (
+	alertRaised       = "raised"
+	alertAcknowledged = "acknowledged"
+	alertUpdated      = "updated"
+	alertCleared      = "cleared"
+)
+
+// alertSnapshot
+// the last seen state of an alert.
+type alertSnapshot struct {
+	id            string
+	timestamp     time.Time
+	severity      int64
+	state         int64
+	acknowledged  bool
+	component     string
+	componentType string
+	messageId     string
+	message       string
+	resolution    string
+}
+
+func newAlertSnapshot(v gjson.Result) *alertSnapshot {
+	return &alertSnapshot{
+		id:            v.Get("id").String(),
+		timestamp:     v.Get("timestamp").Time(),
+		severity:      v.Get("severity").Int(),
+		state:         v.Get("state").Int(),
+		acknowledged:  v.Get("isAcknowledged").Bool(),
+		component:     v.Get("component.id").String(),
+		componentType: v.Get("component.resource").String(),
+		messageId:     v.Get("messageId").String(),
+		message:       v.Get("message").String(),
+		resolution:    v.Get("resolution").String(),
+	}
+}
+
+func (_s *alertSnapshot) isActive() bool {
+	return enum.AlertStateEnum(_s.state) != enum.AlertStateInactive
+}
+
+// transition
+// compare with the previous state, and return the transition ("" when unchanged).
+func (_s *alertSnapshot) transition(prev *alertSnapshot) string {
+	switch {
+	case prev == nil:
+		return alertRaised
+	case prev.isActive() && !_s.isActive():
+		return alertCleared
+	case !prev.acknowledged && _s.acknowledged:
+		return alertAcknowledged
+	case prev.state != _s.state || prev.severity != _s.severity || prev.acknowledged != _s.acknowledged:
+		return alertUpdated
+	}
+	return ""
+}
+
 type ModuleAlert struct {
 	name      string
 	opts      *api.UnityActionOptions
+	desc      []*MetricDescriptor
 	defaults  bool
 	timestamp time.Time
 
+	// Tracked alerts by id, only active alerts are kept.
+	mu     sync.Mutex
+	alerts map[string]*alertSnapshot
+
+	lookbackGaps atomic.Int64
+
 	// Configuration File
-	Enabled *bool `yaml:"enabled,omitempty"`
-	Level   int64 `yaml:"level,omitempty"`
+	Enabled  *bool  `yaml:"enabled,omitempty"`
+	Level    int64  `yaml:"level,omitempty"`
+	Lookback string `yaml:"lookback,omitempty"` // Bound of the window to resume at startup
 }
 
 func NewAlert() *ModuleAlert {
 	return &ModuleAlert{
 		defaults: false,
 		Level:    0,
+		Lookback: "1h",
 	}
 }
 
@@ -42,69 +116,217 @@ func (_m *ModuleAlert) SetConfig(inf interface{}) Module {
 	return _m
 }
 
+func (_m *ModuleAlert) IsEnabled() bool {
+	return isEnabled(_m.Enabled, _m.defaults)
+}
+
 func (_m *ModuleAlert) Init(key string) {
 	_m.name = key
+	_m.alerts = make(map[string]*alertSnapshot)
+	_m.desc = []*MetricDescriptor{
+		{
+			Key:      "active",
+			Name:     "unisphere_alert_active",
+			Desc:     "Number of active alerts by severity and component",
+			Unit:     "",
+			TypeName: "gauge",
+		},
+		{
+			Key:      "gaps",
+			Name:     "unisphere_alert_gaps",
+			Desc:     "count of detected gaps by reason (lookback: not emitted at startup)",
+			Unit:     "",
+			TypeName: "counter",
+		},
+	}
 	_m.opts = api.NewUnityActionOptions("alert")
 	_m.opts.Fields = []string{
+		"id",
 		"timestamp",
 		"severity",
 		"messageId",
 		"message",
+		"state",
+		"isAcknowledged",
+		"component",
+		"resolution",
 	}
 }
 
 func (_m *ModuleAlert) Run(logger *slog.Logger, col *Collector) {
 	opt := *_m.opts
-	ctime := time.Now().Add(-1 * time.Hour).UTC()
 	client := col.Client
 	lp := col.LoggerProvider
 
+	lookback, err := time.ParseDuration(_m.Lookback)
+	if err != nil {
+		logger.Warn("invalid lookback, use default", "module", _m.name, "lookback", _m.Lookback, "err", err)
+		lookback = time.Hour
+	}
+	cursor, _, dropped := col.resumeCursor(_m.name, lookback)
+	if dropped {
+		_m.lookbackGaps.Add(1)
+		logger.Warn("saved cursor is older than lookback, alerts raised in between are not emitted", "module", _m.name, "since", cursor.Timestamp)
+	}
+	logger.Info("resume alerts", "module", _m.name, "since", cursor.Timestamp)
+
+	_m.registerActive(logger, col)
+
 	for {
 		pvlogger := lp.Logger(_m.name, log.WithInstrumentationAttributes(col.detectLabels...))
+		// Active alerts to track their state, and alerts raised since the last poll
 		opt.Filters = []string{
-			"timestamp gt \"" + ctime.Format("2006-01-02T15:04:05.000Z") + "\"",
+			"state ne " + strconv.Itoa(int(enum.AlertStateInactive)) + " or timestamp ge \"" + cursor.Timestamp.UTC().Format("2006-01-02T15:04:05.000Z") + "\"",
 		}
 
-		tmpTime := time.Now().UTC()
-		data, err := client.GetInstances(&opt)
+		data, err := client.GetInstancesContext(col.ctx, &opt)
 		if err != nil {
-			logger.Error("Error to GET AlertLog", "err", err)
-			col.success = false
+			col.handleError(logger, _m.name, err)
 			time.Sleep(col.interval)
 			continue
 		}
 		col.success = true
-		if len(data) == 0 {
-			time.Sleep(col.interval)
-			continue
-		}
 
+		seen := make(map[string]bool)
+		next := cursor
 		for _, v := range data {
-			record := log.Record{}
-			if _m.Level > v.Get("severity").Int() {
+			alert := newAlertSnapshot(v)
+			isNew := cursor.IsNew(alert.timestamp, alert.id)
+			if isNew {
+				next = next.Advance(alert.timestamp, alert.id)
+			}
+			if _m.Level > alert.severity {
 				continue
 			}
+			seen[alert.id] = true
+			_m.track(pvlogger, col, alert, isNew)
+		}
 
-			record.SetTimestamp(v.Get("creationTime").Time())
-			logBody := struct {
-				Message   string `json:"message"`
-				MessageId string `json:"message_id"`
-			}{
-				v.Get("message").String(),
-				v.Get("messageId").String(),
+		// Tracked alerts which are not active anymore...
+		_m.mu.Lock()
+		var missing []*alertSnapshot
+		for id, prev := range _m.alerts {
+			if !seen[id] {
+				missing = append(missing, prev)
 			}
-			record.SetTimestamp(v.Get("timestamp").Time())
-			jsonBody, _ := json.Marshal(logBody)
-			body := gjson.ParseBytes(jsonBody).String()
-			record.SetBody(log.StringValue(body))
-			record.AddAttributes(
-				log.String("level", enum.SeverityEnum(v.Get("severity").Int()).String()),
-			)
-			pvlogger.Emit(col.ctx, record)
+		}
+		_m.mu.Unlock()
+		for _, prev := range missing {
+			idOpt := *_m.opts
+			idOpt.WithId(prev.id)
+			content, err := client.GetInstanceContext(col.ctx, &idOpt)
+			switch {
+			case err == nil:
+				_m.track(pvlogger, col, newAlertSnapshot(content), false)
+			case gounity.IsNotFound(err):
+				// Deleted from the array, it is not active anymore.
+				cleared := *prev
+				cleared.state = int64(enum.AlertStateInactive)
+				_m.track(pvlogger, col, &cleared, false)
+			default:
+				col.handleError(logger, _m.name, err)
+			}
+		}
 
+		if err := col.saveCursor(_m.name, cursor, next); err != nil {
+			logger.Warn("cannot save state", "module", _m.name, "err", err)
 		}
-		ctime = tmpTime
+		cursor = next
 		time.Sleep(col.interval)
 	}
 
 }
+
+// track
+// update the tracked state of the alert, and emit a log record on its transition.
+// alerts which are not new to the cursor are tracked without emitting, those were already active.
+func (_m *ModuleAlert) track(pvlogger log.Logger, col *Collector, alert *alertSnapshot, isNew bool) {
+	_m.mu.Lock()
+	prev := _m.alerts[alert.id]
+	if alert.isActive() {
+		_m.alerts[alert.id] = alert
+	} else {
+		delete(_m.alerts, alert.id)
+	}
+	_m.mu.Unlock()
+
+	transition := alert.transition(prev)
+	if transition == "" || (transition == alertRaised && !isNew) {
+		return
+	}
+
+	record := log.Record{}
+	if transition == alertRaised {
+		record.SetTimestamp(alert.timestamp)
+	} else {
+		record.SetTimestamp(time.Now())
+	}
+	severity := enum.SeverityEnum(alert.severity)
+	record.SetObservedTimestamp(time.Now())
+	record.SetSeverity(severity.LogSeverity())
+	record.SetSeverityText(severity.String())
+	record.SetBody(log.StringValue(alert.message))
+	record.AddAttributes(
+		log.String("level", severity.String()),
+		log.String("alert.id", alert.id),
+		log.String("alert.message_id", alert.messageId),
+		log.String("alert.transition", transition),
+		log.String("alert.state", enum.AlertStateEnum(alert.state).String()),
+		log.Bool("alert.acknowledged", alert.acknowledged),
+		log.String("alert.component", alert.component),
+		log.String("alert.component_type", alert.componentType),
+		log.String("alert.resolution", alert.resolution),
+	)
+	pvlogger.Emit(col.ctx, record)
+}
+
+// registerActive
+// register `unisphere_alert_active` from the tracked alerts, and `unisphere_alert_gaps`.
+func (_m *ModuleAlert) registerActive(logger *slog.Logger, col *Collector) {
+	meter := col.MeterProvider.Meter(_m.name)
+
+	// Register Metrics...
+	var observableMap map[string]metric.Float64Observable
+	observableMap = CreateMapMetricDescriptor(meter, _m.desc, logger)
+
+	// Register Metrics for Observables...
+	var observableArray []metric.Observable
+	for _, obserable := range observableMap {
+		observableArray = append(observableArray, obserable)
+	}
+
+	type activeKey struct {
+		severity      int64
+		component     string
+		componentType string
+	}
+
+	// Callback
+	meter.RegisterCallback(func(ctx context.Context, observer metric.Observer) error {
+
+		// Set Attributes
+		if col.detectLabels == nil {
+			return nil
+		}
+		clientAttrs := metric.WithAttributes(append(col.customLabels, col.detectLabels...)...)
+
+		counts := make(map[activeKey]float64)
+		_m.mu.Lock()
+		for _, alert := range _m.alerts {
+			counts[activeKey{alert.severity, alert.component, alert.componentType}]++
+		}
+		_m.mu.Unlock()
+
+		for k, count := range counts {
+			activeAttrs := metric.WithAttributes(
+				attribute.String("level", enum.SeverityEnum(k.severity).String()),
+				attribute.String("alert.component", k.component),
+				attribute.String("alert.component_type", k.componentType),
+			)
+			observer.ObserveFloat64(observableMap["active"], count, clientAttrs, activeAttrs)
+		}
+		observer.ObserveFloat64(observableMap["gaps"], float64(_m.lookbackGaps.Load()), clientAttrs, metric.WithAttributes(attribute.String("gap.reason", "lookback")))
+
+		return nil
+	}, observableArray...)
+}
@@ -0,0 +1,145 @@
+package collectors
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net"
+	"strings"
+	"time"
+	"unicode"
+	"unisphere_otel_provider/gounity/api"
+	"unisphere_otel_provider/utils/enum"
+
+	"github.com/tidwall/gjson"
+	"go.opentelemetry.io/otel/log"
+)
+
+func init() {
+	key := "audit"
+	registerModule(key, func() Module { return NewAudit() })
+}
+
+// auditAddress
+// source address of the action in the message, the first word which is an IP address (IPv4 or IPv6, with or without port).
+// it is best-effort, empty when the message has no address (e.g. only a hostname).
+func auditAddress(message string) string {
+	for _, word := range strings.Fields(message) {
+		word = strings.Trim(word, ".,;()'\"")
+		if host, _, err := net.SplitHostPort(word); err == nil {
+			word = host
+		}
+		if ip := net.ParseIP(strings.Trim(word, "[]")); ip != nil {
+			return ip.String()
+		}
+	}
+	return ""
+}
+
+// auditFailed
+// the message reports a failure by whole words ("failed", "failure"),
+// so routine messages of failover or failback ("failed over", "failed back") are not failures.
+func auditFailed(message string) bool {
+	words := strings.FieldsFunc(strings.ToLower(message), func(r rune) bool {
+		return !unicode.IsLetter(r)
+	})
+	for i, word := range words {
+		switch word {
+		case "failure":
+			return true
+		case "failed":
+			if i+1 < len(words) && (words[i+1] == "over" || words[i+1] == "back") {
+				continue
+			}
+			return true
+		}
+	}
+	return false
+}
+
+// ModuleAudit
+// emit user and API actions (events with a user) as log records,
+// polled in the same way as the event module, with its own cursor and scope.
+type ModuleAudit struct {
+	// Module's Information
+	name     string
+	opts     *api.UnityActionOptions
+	desc     []*MetricDescriptor
+	defaults bool
+
+	// Configuration File
+	Enabled  *bool  `yaml:"enabled"`
+	Level    int64  `yaml:"level"`
+	Lookback string `yaml:"lookback"` // Bound of the window to resume at startup
+	Overlap  string `yaml:"overlap"`  // Window to query again for events logged late
+	Filter   string `yaml:"filter"`   // Filter of events to be audited
+}
+
+func NewAudit() *ModuleAudit {
+	return &ModuleAudit{
+		defaults: false,
+		Level:    0,
+		Lookback: "1h",
+		Overlap:  "1m",
+		Filter:   "username ne \"\"",
+	}
+}
+
+func (_m *ModuleAudit) SetConfig(inf interface{}) Module {
+	data, _ := json.Marshal(inf)
+	json.NewDecoder(bytes.NewReader(data)).Decode(&_m)
+	return _m
+}
+
+func (_m *ModuleAudit) IsEnabled() bool {
+	return isEnabled(_m.Enabled, _m.defaults)
+}
+
+func (_m *ModuleAudit) Init(key string) {
+	_m.name = key
+	_m.desc = eventPollerDescriptors("unisphere_audit")
+	_m.opts = api.NewUnityActionOptions(string(api.UnityEvent))
+	_m.opts.Fields = []string{"id", "creationTime", "severity", "messageId", "message", "source", "node", "category", "username"}
+}
+
+func (_m *ModuleAudit) Run(logger *slog.Logger, col *Collector) {
+	opts := *_m.opts
+	if _m.Filter != "" {
+		opts.Filters = []string{_m.Filter}
+	}
+	p := newEventPoller(logger, col, _m.name, &opts, _m.Lookback, _m.Overlap)
+	p.register(_m.desc)
+
+	p.run(func(pvlogger log.Logger, v gjson.Result) {
+		if _m.Level > v.Get("severity").Int() {
+			return
+		}
+
+		// Failed actions are logged as errors or more severe
+		severity := enum.SeverityEnum(v.Get("severity").Int())
+		message := v.Get("message").String()
+		result := "success"
+		if severity <= enum.SeverityERROR || auditFailed(message) {
+			result = "failure"
+		}
+
+		record := log.Record{}
+		record.SetTimestamp(v.Get("creationTime").Time())
+		record.SetObservedTimestamp(time.Now())
+		record.SetSeverity(severity.LogSeverity())
+		record.SetSeverityText(severity.String())
+		record.SetBody(log.StringValue(message))
+		record.AddAttributes(
+			log.String("level", severity.String()),
+			log.String("event.id", v.Get("id").String()),
+			log.String("event.source", v.Get("source").String()),
+			log.String("event.node", enumName(eventNodes, v.Get("node").Int())),
+			log.String("event.category", v.Get("category").String()),
+			log.String("user.name", v.Get("username").String()),
+			log.String("client.address", auditAddress(message)),
+			log.String("audit.action", v.Get("messageId").String()),
+			log.String("audit.result", result),
+		)
+		pvlogger.Emit(col.ctx, record)
+	})
+}
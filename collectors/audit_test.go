@@ -0,0 +1,48 @@
+package collectors
+
+import "testing"
+
+func TestAuditAddress(t *testing.T) {
+	tests := []struct {
+		message string
+		want    string
+	}{
+		{"User admin logged in from 192.168.1.10.", "192.168.1.10"},
+		{"User admin logged in from 192.168.1.10:52114", "192.168.1.10"},
+		{"User admin (10.0.0.5) created LUN lun01", "10.0.0.5"},
+		{"User admin logged in from 2001:db8::1.", "2001:db8::1"},
+		{"User admin logged in from [2001:db8::1]:52114", "2001:db8::1"},
+		{"User admin logged in from fe80::a00:27ff:fe4e:66a1", "fe80::a00:27ff:fe4e:66a1"},
+		{"User admin logged in from mgmt01.example.com", ""},
+		{"User admin logged in from mgmt01.example.com:52114", ""},
+		{"Scheduled at 12:30:45 by admin", ""},
+		{"User admin modified the pool", ""},
+	}
+	for _, tt := range tests {
+		if got := auditAddress(tt.message); got != tt.want {
+			t.Errorf("auditAddress(%q) = %q, want %q", tt.message, got, tt.want)
+		}
+	}
+}
+
+func TestAuditFailed(t *testing.T) {
+	tests := []struct {
+		message string
+		want    bool
+	}{
+		{"User admin failed to log in from 192.168.1.10", true},
+		{"Operation FAILED.", true},
+		{"Replication session failure: lost communication", true},
+		{"NAS server nas01 failover completed", false},
+		{"NAS server nas01 failback completed", false},
+		{"NAS server nas01 failed over to SPB", false},
+		{"NAS server nas01 failed-back to SPA", false},
+		{"Failover of NAS server nas01 failed", true},
+		{"User admin created LUN lun01", false},
+	}
+	for _, tt := range tests {
+		if got := auditFailed(tt.message); got != tt.want {
+			t.Errorf("auditFailed(%q) = %v, want %v", tt.message, got, tt.want)
+		}
+	}
+}
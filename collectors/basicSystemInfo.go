@@ -13,7 +13,7 @@ import (
 
 func init() {
 	key := "basicSystemInfo"
-	registerModule(key, NewBasicSystemInfo())
+	registerModule(key, func() Module { return NewBasicSystemInfo() })
 }
 
 type ModuleBasicSystemInfo struct {
@@ -54,6 +54,10 @@ func (_m *ModuleBasicSystemInfo) SetConfig(inf interface{}) Module {
 	return _m
 }
 
+func (_m *ModuleBasicSystemInfo) IsEnabled() bool {
+	return isEnabled(_m.Enabled, _m.defaults)
+}
+
 func (_m *ModuleBasicSystemInfo) Run(logger *slog.Logger, col *Collector) {
 	meter := col.MeterProvider.Meter(_m.name)
 	client := col.Client
@@ -79,9 +83,9 @@ func (_m *ModuleBasicSystemInfo) Run(logger *slog.Logger, col *Collector) {
 		clientAttrs := metric.WithAttributes(append(col.customLabels, col.detectLabels...)...)
 
 		// Request Data
-		data, err := client.GetInstances(_m.opts)
+		data, err := client.GetInstancesContext(ctx, _m.opts)
 		if err != nil {
-			logger.Error("Failed to get", "error", err, "module", _m.name)
+			col.handleError(logger, _m.name, err)
 			return nil
 		}
 
@@ -24,7 +24,7 @@ type ModuleSystemCapacity struct {
 
 func init() {
 	key := "systemCapacity"
-	registerModule(key, NewSystemCapacity())
+	registerModule(key, func() Module { return NewSystemCapacity() })
 }
 
 func NewSystemCapacity() *ModuleSystemCapacity {
@@ -39,6 +39,10 @@ func (_m *ModuleSystemCapacity) SetConfig(inf interface{}) Module {
 	return _m
 }
 
+func (_m *ModuleSystemCapacity) IsEnabled() bool {
+	return isEnabled(_m.Enabled, _m.defaults)
+}
+
 func (_m *ModuleSystemCapacity) Init(key string) {
 	_m.name = key
 	_m.desc = []*MetricDescriptor{
@@ -109,10 +113,9 @@ func (_m *ModuleSystemCapacity) Run(logger *slog.Logger, col *Collector) {
 		clientAttrs := metric.WithAttributes(append(col.customLabels, col.detectLabels...)...)
 
 		// Request Data
-		data, err := client.GetInstances(_m.opts)
+		data, err := client.GetInstancesContext(ctx, _m.opts)
 		if err != nil {
-			logger.Error("Failed to get", "error", err, "module", _m.name)
-			col.success = false
+			col.handleError(logger, _m.name, err)
 			return nil
 		}
 		col.success = true
@@ -4,6 +4,9 @@ import (
 	"context"
 	"errors"
 	"log/slog"
+	"sort"
+	"strconv"
+	"sync"
 	"time"
 	"unisphere_otel_provider/gounity"
 	"unisphere_otel_provider/gounity/api"
@@ -13,19 +16,83 @@ import (
 
 	sdkLog "go.opentelemetry.io/otel/sdk/log"
 	sdkMetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/resource"
 )
 
-var Modules = make(map[string]Module)
+var factories = make(map[string]func() Module)
 
 type Module interface {
 	Run(logger *slog.Logger, col *Collector)
 	Init(key string)
 	SetConfig(inf interface{}) Module
+	IsEnabled() bool
 }
 
-func registerModule(name string, module Module) {
-	module.Init(name)
-	Modules[name] = module
+// Closer
+// module which has resources on the array, released on shutdown.
+type Closer interface {
+	Close(logger *slog.Logger, col *Collector)
+}
+
+func registerModule(name string, factory func() Module) {
+	factories[name] = factory
+}
+
+// isEnabled
+// resolve module's activation, configuration value first and module's default otherwise.
+func isEnabled(enabled *bool, defaults bool) bool {
+	if enabled != nil {
+		return *enabled
+	}
+	return defaults
+}
+
+// ModuleNames
+// return all registered module names in sorted order.
+func ModuleNames() []string {
+	var names []string
+	for k := range factories {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// HasModule
+// check the module is registered.
+func HasModule(name string) bool {
+	_, ok := factories[name]
+	return ok
+}
+
+// NewModules
+// create new module instances from factories, and apply configurations in order.
+// later configuration overrides fields of the former (e.g. global -> client).
+func NewModules(configs ...map[string]interface{}) map[string]Module {
+	modules := make(map[string]Module)
+	for name, factory := range factories {
+		module := factory()
+		module.Init(name)
+		for _, cfg := range configs {
+			if v, ok := cfg[name]; ok {
+				module.SetConfig(v)
+			}
+		}
+		modules[name] = module
+	}
+	return modules
+}
+
+// EnabledModules
+// return names of modules which will be started, in sorted order.
+func EnabledModules(modules map[string]Module) []string {
+	var names []string
+	for _, k := range ModuleNames() {
+		if m, ok := modules[k]; ok && m.IsEnabled() {
+			names = append(names, k)
+		}
+	}
+	return names
 }
 
 type Collector struct {
@@ -37,7 +104,21 @@ type Collector struct {
 	LoggerProvider *sdkLog.LoggerProvider
 	interval       time.Duration
 	Client         *gounity.UnisphereClient
+	Modules        map[string]Module
+	MetricExporter sdkMetric.Exporter // Export data points with their own timestamps
+	Resource       *resource.Resource
+	State          *StateFile // Cursors of log modules, kept in memory only when nil
 	success        bool
+
+	errMu    sync.Mutex
+	errCount map[scrapeError]int64
+}
+
+// scrapeError
+// key of error counts, by module and error type.
+type scrapeError struct {
+	module    string
+	errorType string
 }
 
 func NewCollector(ctx context.Context, attrs map[string]string, interval time.Duration) *Collector {
@@ -49,6 +130,7 @@ func NewCollector(ctx context.Context, attrs map[string]string, interval time.Du
 		ctx:          ctx,
 		customLabels: customLabels,
 		interval:     interval,
+		errCount:     make(map[scrapeError]int64),
 	}
 }
 
@@ -56,7 +138,7 @@ func (_col *Collector) Start(logger *slog.Logger) {
 	// Init HostName
 	opt := api.NewUnityActionOptions("system")
 	opt.Fields = []string{"name"}
-	data, err := _col.Client.GetInstances(opt)
+	data, err := _col.Client.GetInstancesContext(_col.ctx, opt)
 	if err != nil {
 		logger.Warn("cannot set labels", "error", err)
 	} else {
@@ -66,12 +148,83 @@ func (_col *Collector) Start(logger *slog.Logger) {
 
 	}
 
-	for _, v := range Modules {
-		go v.Run(logger, _col)
+	names := EnabledModules(_col.Modules)
+	logger.Info("start collector", "instance", _col.Instance, "collectors", names)
+	for _, k := range names {
+		go _col.Modules[k].Run(logger, _col)
 	}
 	select {}
 }
 
+// errorType
+// classify the error of Unisphere client.
+func errorType(err error) string {
+	switch {
+	case errors.Is(err, gounity.ErrCircuitOpen):
+		return "circuit_open"
+	case gounity.IsNotFound(err):
+		return "not_found"
+	case gounity.IsAuth(err):
+		return "auth"
+	case gounity.IsRetryable(err):
+		return "transient"
+	}
+	return "other"
+}
+
+// handleError
+// count the error by type, and log it.
+// not found means the object type is not supported on this model,
+// so it does not mark the scrape as failed.
+func (_col *Collector) handleError(logger *slog.Logger, module string, err error) {
+	errType := errorType(err)
+	_col.errMu.Lock()
+	_col.errCount[scrapeError{module: module, errorType: errType}]++
+	_col.errMu.Unlock()
+
+	switch errType {
+	case "not_found":
+		logger.Debug("not supported on this system", "error", err, "module", module)
+		return
+	case "circuit_open":
+		logger.Debug("skip request while the array is down", "error", err, "module", module)
+		_col.success = false
+		return
+	}
+	logger.Error("Failed to get", "error", err, "module", module, "error_type", errType)
+	_col.success = false
+}
+
+// errorCounts
+// return a copy of error counts.
+func (_col *Collector) errorCounts() map[scrapeError]int64 {
+	_col.errMu.Lock()
+	defer _col.errMu.Unlock()
+	counts := make(map[scrapeError]int64, len(_col.errCount))
+	for k, v := range _col.errCount {
+		counts[k] = v
+	}
+	return counts
+}
+
+// Shutdown
+// release resources of the collector on the array (e.g. login session).
+func (_col *Collector) Shutdown(logger *slog.Logger) {
+	for _, k := range EnabledModules(_col.Modules) {
+		if closer, ok := _col.Modules[k].(Closer); ok {
+			closer.Close(logger, _col)
+		}
+	}
+	if _col.MetricExporter != nil {
+		if err := _col.MetricExporter.Shutdown(_col.ctx); err != nil {
+			logger.Warn("cannot shutdown metric exporter", "instance", _col.Instance, "error", err)
+		}
+	}
+	if err := _col.Client.Logout(_col.ctx); err != nil {
+		logger.Warn("cannot logout", "instance", _col.Instance, "error", err)
+	}
+}
+
 type MetricDescriptor struct {
 	Key      string
 	Name     string
@@ -103,3 +256,12 @@ func CreateMapMetricDescriptor(meter metric.Meter, mds []*MetricDescriptor, logg
 	return mdmap
 
 }
+
+// enumName
+// name of the enum value, or the number when it is unknown.
+func enumName(names map[int64]string, v int64) string {
+	if name, ok := names[v]; ok {
+		return name
+	}
+	return strconv.FormatInt(v, 10)
+}
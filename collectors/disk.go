@@ -4,7 +4,11 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"log/slog"
+	"strconv"
+	"sync/atomic"
+	"unisphere_otel_provider/gounity"
 	"unisphere_otel_provider/gounity/api"
 	"unisphere_otel_provider/utils"
 
@@ -14,16 +18,46 @@ import (
 
 func init() {
 	key := "disk"
-	registerModule(key, NewDisk())
+	registerModule(key, func() Module { return NewDisk() })
 }
 
+// diskTechnologies
+// DiskTechnologyEnum of Unisphere
+var diskTechnologies = map[int64]string{
+	1:  "SAS",
+	2:  "NL_SAS",
+	6:  "SAS_FLASH_2",
+	7:  "SAS_FLASH_3",
+	8:  "SAS_FLASH_4",
+	50: "Mixed",
+	99: "Virtual",
+}
+
+// diskTierTypes
+// TierTypeEnum of Unisphere
+var diskTierTypes = map[int64]string{
+	0:  "None",
+	10: "Extreme_Performance",
+	20: "Performance",
+	30: "Capacity",
+}
+
+// diskTierFlash
+// flash drives are in the Extreme Performance tier.
+const diskTierFlash = 10
+
 type ModuleDisk struct {
 	// Module's Information
-	name     string
-	opts     *api.UnityActionOptions
-	desc     []*MetricDescriptor
-	defaults bool
-	labels   []string
+	name          string
+	opts          *api.UnityActionOptions
+	enduranceOpts *api.UnityActionOptions
+	desc          []*MetricDescriptor
+	defaults      bool
+	labels        []string
+
+	// Endurance fields are not available on all arrays,
+	// and the query is not sent again once the array rejects it.
+	enduranceUnsupported atomic.Bool
 
 	// Configuration File
 	Enabled *bool
@@ -66,16 +100,69 @@ func (_m *ModuleDisk) Init(key string) {
 			Unit:     "",
 			TypeName: "gauge",
 		},
+		{
+			Key:      "rawSize",
+			Name:     "unisphere_disk_raw_size",
+			Desc:     "Raw capacity",
+			Unit:     "mb",
+			TypeName: "gauge",
+		},
+		{
+			Key:      "rpm",
+			Name:     "unisphere_disk_rpm",
+			Desc:     "Revolutions per minute of the drive, 0 for flash drives",
+			Unit:     "",
+			TypeName: "gauge",
+		},
+		{
+			Key:      "isFastCacheInUse",
+			Name:     "unisphere_disk_is_fast_cache_in_use",
+			Desc:     "Indicates whether the drive is used by FAST Cache",
+			Unit:     "",
+			TypeName: "gauge",
+		},
+		// Flash drive's endurance...
+		{
+			Key:      "pctWritesUsed",
+			Name:     "unisphere_disk_ssd_writes_used_percent",
+			Desc:     "Percentage of rated write endurance used by the flash drive",
+			Unit:     "%",
+			TypeName: "gauge",
+		},
+		{
+			Key:      "remainingLife",
+			Name:     "unisphere_disk_ssd_remaining_life_percent",
+			Desc:     "Percentage of rated write endurance remaining of the flash drive",
+			Unit:     "%",
+			TypeName: "gauge",
+		},
+	}
+	_m.labels = []string{
+		"id",
+		"name",
+		"model",
+		"emcPartNumber",
+		"emcSerialNumber",
+		"slotNumber",
+		"tierType",
+		"diskTechnology",
+		"pool.id",
+		"busId",
+		"parentDae.id",
 	}
-	_m.labels = []string{"id", "name", "model", "emcPartNumber", "slotNumber"}
 	_m.opts = api.NewUnityActionOptions("disk")
 	for _, v := range _m.desc {
-		if v.Key == "info" {
+		switch v.Key {
+		case "info", "pctWritesUsed", "remainingLife":
 			continue
 		}
 		_m.opts.Fields = append(_m.opts.Fields, v.Key)
 	}
 	_m.opts.Fields = append(_m.opts.Fields, _m.labels...)
+
+	_m.enduranceOpts = api.NewUnityActionOptions("disk")
+	_m.enduranceOpts.Fields = []string{"id", "pctWritesUsed"}
+	_m.enduranceOpts.Filters = []string{"tierType eq " + strconv.Itoa(diskTierFlash)}
 }
 
 func (_m *ModuleDisk) SetConfig(inf interface{}) Module {
@@ -84,6 +171,10 @@ func (_m *ModuleDisk) SetConfig(inf interface{}) Module {
 	return _m
 }
 
+func (_m *ModuleDisk) IsEnabled() bool {
+	return isEnabled(_m.Enabled, _m.defaults)
+}
+
 func (_m *ModuleDisk) Run(logger *slog.Logger, col *Collector) {
 	meter := col.MeterProvider.Meter(_m.name)
 	client := col.Client
@@ -108,14 +199,32 @@ func (_m *ModuleDisk) Run(logger *slog.Logger, col *Collector) {
 		clientAttrs := metric.WithAttributes(append(col.customLabels, col.detectLabels...)...)
 
 		// Request Data
-		data, err := client.GetInstances(_m.opts)
+		data, err := client.GetInstancesContext(ctx, _m.opts)
 		if err != nil {
-			logger.Error("Failed to get", "error", err, "module", _m.name)
-			col.success = false
+			col.handleError(logger, _m.name, err)
 			return nil
 		}
 		col.success = true
 
+		// Flash drive's endurance...
+		endurance := make(map[string]float64)
+		if !_m.enduranceUnsupported.Load() {
+			flash, err := client.GetInstancesContext(ctx, _m.enduranceOpts)
+			switch {
+			case err == nil:
+				for _, v := range flash {
+					if v.Get("pctWritesUsed").Exists() {
+						endurance[v.Get("id").String()] = v.Get("pctWritesUsed").Float()
+					}
+				}
+			case gounity.IsRetryable(err) || errors.Is(err, gounity.ErrCircuitOpen) || errors.Is(err, context.Canceled):
+				col.handleError(logger, _m.name, err)
+			default:
+				logger.Info("flash drive's endurance is not available", "module", _m.name, "err", err)
+				_m.enduranceUnsupported.Store(true)
+			}
+		}
+
 		// Capacity Attributes...
 		for _, v := range data {
 			diskAttrs := metric.WithAttributes(
@@ -125,7 +234,14 @@ func (_m *ModuleDisk) Run(logger *slog.Logger, col *Collector) {
 			infoAttrs := metric.WithAttributes(
 				attribute.String("disk.model", v.Get("model").String()),
 				attribute.String("disk.part", v.Get("emcPartNumber").String()),
+				attribute.String("disk.serial", v.Get("emcSerialNumber").String()),
+				attribute.String("disk.tier", enumName(diskTierTypes, v.Get("tierType").Int())),
+				attribute.String("disk.technology", enumName(diskTechnologies, v.Get("diskTechnology").Int())),
+				attribute.String("pool.id", v.Get("pool.id").String()),
+				attribute.String("bus.id", v.Get("busId").String()),
+				attribute.String("dae.id", v.Get("parentDae.id").String()),
 			)
+			pctWritesUsed, isFlash := endurance[v.Get("id").String()]
 			for _, desc := range _m.desc {
 				key := desc.Key
 				switch key {
@@ -138,9 +254,19 @@ func (_m *ModuleDisk) Run(logger *slog.Logger, col *Collector) {
 					continue
 				case "health.value":
 					observer.ObserveFloat64(observableMap[key], v.Get(key).Float(), clientAttrs, diskAttrs)
-				case "size":
+				case "size", "rawSize":
 					observer.ObserveFloat64(observableMap[key], utils.Bytes(v.Get(key).Int()).ToMiB(), clientAttrs, diskAttrs)
-				case "isInUse":
+				case "rpm":
+					observer.ObserveFloat64(observableMap[key], v.Get(key).Float(), clientAttrs, diskAttrs)
+				case "pctWritesUsed":
+					if isFlash {
+						observer.ObserveFloat64(observableMap[key], pctWritesUsed, clientAttrs, diskAttrs)
+					}
+				case "remainingLife":
+					if isFlash {
+						observer.ObserveFloat64(observableMap[key], max(100-pctWritesUsed, 0), clientAttrs, diskAttrs)
+					}
+				case "isInUse", "isFastCacheInUse":
 					var f float64
 					if v.Get(key).Bool() {
 						f = 1
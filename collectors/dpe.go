@@ -13,25 +13,27 @@ import (
 
 func init() {
 	key := "dpe"
-	registerModule(key, NewDPE())
+	registerModule(key, func() Module { return NewDPE() })
 }
 
 type ModuleDPE struct {
 	// Module's Information
-	name  string
-	opts  *api.UnityActionOptions
-	descs []*MetricDescriptor
+	name     string
+	opts     *api.UnityActionOptions
+	descs    []*MetricDescriptor
+	defaults bool
 
 	// Configuration File
-	Enabled bool `yaml:"enabled"`
+	Enabled *bool `yaml:"enabled"`
 }
 
 func NewDPE() *ModuleDPE {
-	return &ModuleDPE{}
+	return &ModuleDPE{
+		defaults: true,
+	}
 }
 
 func (_m *ModuleDPE) Init(key string) {
-	_m.Enabled = true
 	_m.name = key
 	_m.descs = []*MetricDescriptor{
 		{
@@ -65,6 +67,10 @@ func (_m *ModuleDPE) SetConfig(inf interface{}) Module {
 	return _m
 }
 
+func (_m *ModuleDPE) IsEnabled() bool {
+	return isEnabled(_m.Enabled, _m.defaults)
+}
+
 func (_m *ModuleDPE) Run(logger *slog.Logger, col *Collector) {
 	meter := col.MeterProvider.Meter(_m.name)
 	client := col.Client
@@ -90,9 +96,9 @@ func (_m *ModuleDPE) Run(logger *slog.Logger, col *Collector) {
 		clientAttrs := metric.WithAttributes(append(col.customLabels, col.detectLabels...)...)
 
 		// Request Data
-		data, err := client.GetInstances(_m.opts)
+		data, err := client.GetInstancesContext(ctx, _m.opts)
 		if err != nil {
-			logger.Error("Failed to get", "error", err, "module", _m.name)
+			col.handleError(logger, _m.name, err)
 			return nil
 		}
 
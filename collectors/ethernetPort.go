@@ -13,7 +13,7 @@ import (
 
 func init() {
 	key := "ethernetPort"
-	registerModule(key, NewEthernetPort())
+	registerModule(key, func() Module { return NewEthernetPort() })
 }
 
 func (_m *ModuleEthernetPort) SetConfig(inf interface{}) Module {
@@ -22,6 +22,10 @@ func (_m *ModuleEthernetPort) SetConfig(inf interface{}) Module {
 	return _m
 }
 
+func (_m *ModuleEthernetPort) IsEnabled() bool {
+	return isEnabled(_m.Enabled, _m.defaults)
+}
+
 type ModuleEthernetPort struct {
 	// Module's Information
 	name     string
@@ -107,10 +111,9 @@ func (_m *ModuleEthernetPort) Run(logger *slog.Logger, col *Collector) {
 		clientAttrs := metric.WithAttributes(append(col.customLabels, col.detectLabels...)...)
 
 		// Request Data
-		data, err := client.GetInstances(_m.opts)
+		data, err := client.GetInstancesContext(ctx, _m.opts)
 		if err != nil {
-			logger.Error("Failed to get", "error", err, "module", _m.name)
-			col.success = false
+			col.handleError(logger, _m.name, err)
 			return nil
 		}
 		col.success = true
@@ -2,37 +2,54 @@ package collectors
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"log/slog"
+	"slices"
+	"sync/atomic"
 	"time"
 	"unisphere_otel_provider/gounity/api"
 	"unisphere_otel_provider/utils/enum"
 
 	"github.com/tidwall/gjson"
+	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/log"
+	"go.opentelemetry.io/otel/metric"
 )
 
 func init() {
 	key := "event"
-	registerModule(key, NewEvent())
+	registerModule(key, func() Module { return NewEvent() })
+}
+
+// eventNodes
+// NodeEnum of Unisphere
+var eventNodes = map[int64]string{
+	0: "SPA",
+	1: "SPB",
 }
 
 type ModuleEvent struct {
 	// Module's Information
 	name      string
 	opts      *api.UnityActionOptions
+	desc      []*MetricDescriptor
 	defaults  bool // Default Enabled
 	timestamp time.Time
 
 	// Configuration File
-	Enabled *bool `yaml:"enabled"`
-	Level   int64 `yaml:"level"`
+	Enabled  *bool  `yaml:"enabled"`
+	Level    int64  `yaml:"level"`
+	Lookback string `yaml:"lookback"` // Bound of the window to resume at startup
+	Overlap  string `yaml:"overlap"`  // Window to query again for events logged late
 }
 
 func NewEvent() *ModuleEvent {
 	return &ModuleEvent{
 		defaults: false,
 		Level:    5,
+		Lookback: "1h",
+		Overlap:  "1m",
 	}
 }
 
@@ -42,66 +59,232 @@ func (_m *ModuleEvent) SetConfig(inf interface{}) Module {
 	return _m
 }
 
+func (_m *ModuleEvent) IsEnabled() bool {
+	return isEnabled(_m.Enabled, _m.defaults)
+}
+
 func (_m *ModuleEvent) Init(key string) {
 	_m.name = key
+	_m.desc = eventPollerDescriptors("unisphere_event")
 	_m.opts = api.NewUnityActionOptions("event")
-	_m.opts.Fields = []string{"creationTime", "severity", "messageId", "message", "source"}
+	_m.opts.Fields = []string{"id", "creationTime", "severity", "messageId", "message", "source", "node", "category", "username"}
 }
 
 func (_m *ModuleEvent) Run(logger *slog.Logger, col *Collector) {
-	opt := *_m.opts
-	ctime := time.Now().Add(-1 * time.Hour).UTC()
-	client := col.Client
-	lp := col.LoggerProvider
+	p := newEventPoller(logger, col, _m.name, _m.opts, _m.Lookback, _m.Overlap)
+	p.register(_m.desc)
 
-	for {
-		pvlogger := lp.Logger(_m.name, log.WithInstrumentationAttributes(col.detectLabels...))
-		opt.Filters = []string{
-			"creationTime gt \"" + ctime.Format("2006-01-02T15:04:05.000Z") + "\"",
+	p.run(func(pvlogger log.Logger, v gjson.Result) {
+		if _m.Level > v.Get("severity").Int() {
+			return
 		}
 
-		tmpTime := time.Now().UTC()
-		data, err := client.GetInstances(&opt)
-		if err != nil {
-			logger.Error("Error to GET EventLog", "err", err)
-			col.success = false
-			time.Sleep(col.interval)
+		record := log.Record{}
+		severity := enum.SeverityEnum(v.Get("severity").Int())
+		record.SetTimestamp(v.Get("creationTime").Time())
+		record.SetObservedTimestamp(time.Now())
+		record.SetSeverity(severity.LogSeverity())
+		record.SetSeverityText(severity.String())
+		record.SetBody(log.StringValue(v.Get("message").String()))
+		record.AddAttributes(
+			log.String("level", severity.String()),
+			log.String("event.id", v.Get("id").String()),
+			log.String("event.message_id", v.Get("messageId").String()),
+			log.String("event.source", v.Get("source").String()),
+			log.String("event.node", enumName(eventNodes, v.Get("node").Int())),
+			log.String("event.category", v.Get("category").String()),
+			log.String("user.name", v.Get("username").String()),
+		)
+		pvlogger.Emit(col.ctx, record)
+	})
+}
+
+// eventPollerDescriptors
+// metrics of the event poller, named with the prefix.
+func eventPollerDescriptors(prefix string) []*MetricDescriptor {
+	return []*MetricDescriptor{
+		{
+			Key:      "duplicates",
+			Name:     prefix + "_duplicates",
+			Desc:     "count of records returned again after the cursor passed them, and suppressed",
+			Unit:     "",
+			TypeName: "counter",
+		},
+		{
+			Key:      "gaps",
+			Name:     prefix + "_gaps",
+			Desc:     "count of detected gaps by reason (late: recovered by overlap, lookback: not emitted at startup)",
+			Unit:     "",
+			TypeName: "counter",
+		},
+	}
+}
+
+// eventPoller
+// poll records of event type by their creationTime.
+// the next poll starts from the newest creationTime returned (minus overlap, not the local clock),
+// and records are deduplicated by ids of recent records.
+type eventPoller struct {
+	logger  *slog.Logger
+	col     *Collector
+	name    string
+	opts    *api.UnityActionOptions
+	overlap time.Duration
+
+	cursor Cursor
+	seen   map[string]time.Time // Recent ids, within overlap of the cursor
+	floor  time.Time            // Records older than floor are not emitted
+
+	duplicates   atomic.Int64
+	lateGaps     atomic.Int64
+	lookbackGaps atomic.Int64
+}
+
+func newEventPoller(logger *slog.Logger, col *Collector, name string, opts *api.UnityActionOptions, lookback string, overlap string) *eventPoller {
+	p := &eventPoller{
+		logger: logger,
+		col:    col,
+		name:   name,
+		opts:   opts,
+		seen:   make(map[string]time.Time),
+	}
+
+	lookbackDuration, err := time.ParseDuration(lookback)
+	if err != nil {
+		logger.Warn("invalid lookback, use default", "module", name, "lookback", lookback, "err", err)
+		lookbackDuration = time.Hour
+	}
+	p.overlap, err = time.ParseDuration(overlap)
+	if err != nil {
+		logger.Warn("invalid overlap, use default", "module", name, "overlap", overlap, "err", err)
+		p.overlap = time.Minute
+	}
+
+	cursor, resumed, dropped := col.resumeCursor(name, lookbackDuration)
+	p.cursor = cursor
+	for _, id := range cursor.IDs {
+		p.seen[id] = cursor.Timestamp
+	}
+	// Ids in the overlap are unknown without saved state.
+	if !resumed {
+		p.floor = cursor.Timestamp
+	}
+	if dropped {
+		p.lookbackGaps.Add(1)
+		logger.Warn("saved cursor is older than lookback, records in between are not emitted", "module", name, "since", cursor.Timestamp)
+	}
+	logger.Info("resume records", "module", name, "since", cursor.Timestamp)
+	return p
+}
+
+// register
+// register counters of duplicates and gaps.
+func (_p *eventPoller) register(desc []*MetricDescriptor) {
+	meter := _p.col.MeterProvider.Meter(_p.name)
+
+	// Register Metrics...
+	var observableMap map[string]metric.Float64Observable
+	observableMap = CreateMapMetricDescriptor(meter, desc, _p.logger)
+
+	// Register Metrics for Observables...
+	var observableArray []metric.Observable
+	for _, obserable := range observableMap {
+		observableArray = append(observableArray, obserable)
+	}
+
+	// Callback
+	meter.RegisterCallback(func(ctx context.Context, observer metric.Observer) error {
+
+		// Set Attributes
+		if _p.col.detectLabels == nil {
+			return nil
+		}
+		clientAttrs := metric.WithAttributes(append(_p.col.customLabels, _p.col.detectLabels...)...)
+
+		observer.ObserveFloat64(observableMap["duplicates"], float64(_p.duplicates.Load()), clientAttrs)
+		observer.ObserveFloat64(observableMap["gaps"], float64(_p.lateGaps.Load()), clientAttrs, metric.WithAttributes(attribute.String("gap.reason", "late")))
+		observer.ObserveFloat64(observableMap["gaps"], float64(_p.lookbackGaps.Load()), clientAttrs, metric.WithAttributes(attribute.String("gap.reason", "lookback")))
+
+		return nil
+	}, observableArray...)
+}
+
+// filter
+// return new records of a poll from `from`, and the next cursor.
+// records of known ids in the overlap are expected to be returned again, and skipped silently.
+// duplicates are records returned again which the cursor has already passed:
+// the same id twice in a poll (e.g. pages shifted by new records), or a record older than `from`.
+func (_p *eventPoller) filter(data []gjson.Result, from time.Time) ([]gjson.Result, Cursor) {
+	var records []gjson.Result
+	polled := make(map[string]bool)
+	next := _p.cursor.Timestamp
+	for _, v := range data {
+		id := v.Get("id").String()
+		timestamp := v.Get("creationTime").Time()
+		if polled[id] || timestamp.Before(from) {
+			_p.duplicates.Add(1)
 			continue
 		}
-		col.success = true
-		if data == nil {
-			time.Sleep(col.interval)
+		polled[id] = true
+		if _, ok := _p.seen[id]; ok || timestamp.Before(_p.floor) {
+			continue
+		}
+		_p.seen[id] = timestamp
+		if timestamp.Before(_p.cursor.Timestamp) {
+			_p.lateGaps.Add(1)
+			_p.logger.Debug("record is logged late", "module", _p.name, "id", id, "timestamp", timestamp)
+		}
+		if timestamp.After(next) {
+			next = timestamp
+		}
+		records = append(records, v)
+	}
+
+	// Forget ids out of the overlap...
+	var ids []string
+	for id, timestamp := range _p.seen {
+		if timestamp.Before(next.Add(-_p.overlap)) {
+			delete(_p.seen, id)
 			continue
 		}
+		ids = append(ids, id)
+	}
+	slices.Sort(ids)
+
+	return records, Cursor{Timestamp: next, IDs: ids}
+}
 
-		for _, v := range data {
-			record := log.Record{}
-			if _m.Level > v.Get("severity").Int() {
-				continue
-			}
-
-			record.SetTimestamp(v.Get("creationTime").Time())
-			logBody := struct {
-				Source    string `json:"source"`
-				Message   string `json:"message"`
-				MessageId string `json:"message_id"`
-			}{
-				v.Get("source").String(),
-				v.Get("message").String(),
-				v.Get("messageId").String(),
-			}
-			jsonBody, _ := json.Marshal(logBody)
-			body := gjson.ParseBytes(jsonBody).String()
-			record.SetBody(log.StringValue(body))
-			record.AddAttributes(
-				log.String("level", enum.SeverityEnum(v.Get("severity").Int()).String()),
-			)
-			pvlogger.Emit(col.ctx, record)
+// run
+// poll records, and call emit for each new record.
+func (_p *eventPoller) run(emit func(pvlogger log.Logger, v gjson.Result)) {
+	col := _p.col
+	opt := *_p.opts
+
+	for {
+		pvlogger := col.LoggerProvider.Logger(_p.name, log.WithInstrumentationAttributes(col.detectLabels...))
+		from := _p.cursor.Timestamp.Add(-_p.overlap)
+		filter := "creationTime ge \"" + from.UTC().Format("2006-01-02T15:04:05.000Z") + "\""
+		for _, f := range _p.opts.Filters {
+			filter = f + " and " + filter
+		}
+		opt.Filters = []string{filter}
 
+		data, err := col.Client.GetInstancesContext(col.ctx, &opt)
+		if err != nil {
+			col.handleError(_p.logger, _p.name, err)
+			time.Sleep(col.interval)
+			continue
 		}
+		col.success = true
 
-		ctime = tmpTime
+		records, cursor := _p.filter(data, from)
+		for _, v := range records {
+			emit(pvlogger, v)
+		}
+		if err := col.saveCursor(_p.name, _p.cursor, cursor); err != nil {
+			_p.logger.Warn("cannot save state", "module", _p.name, "err", err)
+		}
+		_p.cursor = cursor
 		time.Sleep(col.interval)
 	}
-
 }
@@ -0,0 +1,135 @@
+package collectors
+
+import (
+	"io"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/tidwall/gjson"
+)
+
+// eventRecords
+// records of events, each of them is "id@seconds" from base.
+func eventRecords(base time.Time, events ...string) []gjson.Result {
+	var items []string
+	for _, e := range events {
+		id, offset, _ := strings.Cut(e, "@")
+		d, _ := time.ParseDuration(offset + "s")
+		items = append(items, `{"id":"`+id+`","creationTime":"`+base.Add(d).UTC().Format("2006-01-02T15:04:05.000Z")+`"}`)
+	}
+	return gjson.Parse("[" + strings.Join(items, ",") + "]").Array()
+}
+
+func recordIDs(records []gjson.Result) []string {
+	var ids []string
+	for _, v := range records {
+		ids = append(ids, v.Get("id").String())
+	}
+	return ids
+}
+
+func TestEventPollerFilter(t *testing.T) {
+	base := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	// poll is a response of the array, and its expected result.
+	type poll struct {
+		events     []string
+		emitted    []string
+		duplicates int64
+		lateGaps   int64
+	}
+	tests := []struct {
+		name  string
+		floor time.Time
+		polls []poll
+	}{
+		{"quiet array", time.Time{}, []poll{
+			{events: []string{"a@0", "b@10"}, emitted: []string{"a", "b"}},
+			{events: []string{"a@0", "b@10"}},
+			{events: []string{"b@10"}},
+			{events: []string{"b@10"}},
+		}},
+		{"new records", time.Time{}, []poll{
+			{events: []string{"a@0"}, emitted: []string{"a"}},
+			{events: []string{"a@0", "b@10", "c@10"}, emitted: []string{"b", "c"}},
+			{events: []string{"b@10", "c@10", "d@20"}, emitted: []string{"d"}},
+		}},
+		{"same timestamp in later poll", time.Time{}, []poll{
+			{events: []string{"a@10"}, emitted: []string{"a"}},
+			{events: []string{"a@10", "b@10"}, emitted: []string{"b"}},
+		}},
+		{"logged late", time.Time{}, []poll{
+			{events: []string{"a@0", "b@30"}, emitted: []string{"a", "b"}},
+			{events: []string{"a@0", "c@20", "b@30"}, emitted: []string{"c"}, lateGaps: 1},
+		}},
+		{"shifted pages", time.Time{}, []poll{
+			{events: []string{"a@0", "b@10", "b@10", "c@20"}, emitted: []string{"a", "b", "c"}, duplicates: 1},
+			{events: []string{"c@20", "c@20"}, duplicates: 1},
+		}},
+		{"older than the window", time.Time{}, []poll{
+			{events: []string{"a@100"}, emitted: []string{"a"}},
+			{events: []string{"z@0", "a@100"}, duplicates: 1},
+		}},
+		{"older than the floor", base.Add(50 * time.Second), []poll{
+			{events: []string{"a@20", "b@60"}, emitted: []string{"b"}},
+			{events: []string{"a@20", "b@60"}},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := &eventPoller{
+				logger:  slog.New(slog.NewTextHandler(io.Discard, nil)),
+				overlap: time.Minute,
+				cursor:  Cursor{Timestamp: base},
+				seen:    make(map[string]time.Time),
+				floor:   tt.floor,
+			}
+			for i, poll := range tt.polls {
+				from := p.cursor.Timestamp.Add(-p.overlap)
+				records, cursor := p.filter(eventRecords(base, poll.events...), from)
+				p.cursor = cursor
+
+				if got := strings.Join(recordIDs(records), ","); got != strings.Join(poll.emitted, ",") {
+					t.Errorf("poll %d: emitted = [%s], want [%s]", i, got, strings.Join(poll.emitted, ","))
+				}
+				if got := p.duplicates.Swap(0); got != poll.duplicates {
+					t.Errorf("poll %d: duplicates = %d, want %d", i, got, poll.duplicates)
+				}
+				if got := p.lateGaps.Swap(0); got != poll.lateGaps {
+					t.Errorf("poll %d: late gaps = %d, want %d", i, got, poll.lateGaps)
+				}
+			}
+		})
+	}
+}
+
+func TestEventPollerFilterCursor(t *testing.T) {
+	base := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	p := &eventPoller{
+		logger:  slog.New(slog.NewTextHandler(io.Discard, nil)),
+		overlap: time.Minute,
+		cursor:  Cursor{Timestamp: base},
+		seen:    make(map[string]time.Time),
+	}
+
+	// The cursor moves to the newest record, and keeps ids within the overlap of it.
+	_, cursor := p.filter(eventRecords(base, "a@0", "b@90", "c@120"), base.Add(-time.Minute))
+	if want := base.Add(120 * time.Second); !cursor.Timestamp.Equal(want) {
+		t.Errorf("cursor = %v, want %v", cursor.Timestamp, want)
+	}
+	if got := strings.Join(cursor.IDs, ","); got != "b,c" {
+		t.Errorf("ids = [%s], want [b,c]", got)
+	}
+	if _, ok := p.seen["a"]; ok {
+		t.Errorf("id out of the overlap is kept")
+	}
+
+	// The cursor does not go back on an empty poll.
+	p.cursor = cursor
+	_, cursor = p.filter(nil, cursor.Timestamp.Add(-time.Minute))
+	if !cursor.Timestamp.Equal(p.cursor.Timestamp) {
+		t.Errorf("cursor = %v, want %v", cursor.Timestamp, p.cursor.Timestamp)
+	}
+}
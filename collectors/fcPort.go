@@ -14,7 +14,7 @@ import (
 
 func init() {
 	key := "fcPort"
-	registerModule(key, NewFcPort())
+	registerModule(key, func() Module { return NewFcPort() })
 }
 
 type ModuleFcPort struct {
@@ -77,6 +77,10 @@ func (_m *ModuleFcPort) SetConfig(inf interface{}) Module {
 	return _m
 }
 
+func (_m *ModuleFcPort) IsEnabled() bool {
+	return isEnabled(_m.Enabled, _m.defaults)
+}
+
 func (_m *ModuleFcPort) Run(logger *slog.Logger, col *Collector) {
 	meter := col.MeterProvider.Meter(_m.name)
 	client := col.Client
@@ -101,10 +105,9 @@ func (_m *ModuleFcPort) Run(logger *slog.Logger, col *Collector) {
 		clientAttrs := metric.WithAttributes(append(col.customLabels, col.detectLabels...)...)
 
 		// Request Data
-		data, err := client.GetInstances(_m.opts)
+		data, err := client.GetInstancesContext(ctx, _m.opts)
 		if err != nil {
-			logger.Error("Failed to get", "error", err, "module", _m.name)
-			col.success = false
+			col.handleError(logger, _m.name, err)
 			return nil
 		}
 		col.success = true
@@ -0,0 +1,214 @@
+package collectors
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"unisphere_otel_provider/gounity/api"
+	"unisphere_otel_provider/utils"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+func init() {
+	key := "filesystem"
+	registerModule(key, func() Module { return NewFilesystem() })
+}
+
+// filesystemProtocols
+// FSSupportedProtocolEnum of Unisphere
+var filesystemProtocols = map[int64]string{
+	0: "NFS",
+	1: "CIFS",
+	2: "Multiprotocol",
+}
+
+type ModuleFilesystem struct {
+	// Module's Information
+	name     string
+	opts     *api.UnityActionOptions
+	desc     []*MetricDescriptor
+	defaults bool
+
+	// Configuration File
+	Enabled *bool `yaml:"enabled"`
+}
+
+func NewFilesystem() *ModuleFilesystem {
+	return &ModuleFilesystem{
+		defaults: false,
+	}
+}
+
+func (_m *ModuleFilesystem) Init(key string) {
+	_m.name = key
+	_m.desc = []*MetricDescriptor{
+		{
+			Key:      "info",
+			Name:     "unisphere_filesystem_info",
+			Desc:     "information of the associated resource",
+			Unit:     "",
+			TypeName: "gauge",
+		},
+		{
+			Key:      "health.value",
+			Name:     "unisphere_filesystem_health",
+			Desc:     "Health of the associated resource",
+			Unit:     "",
+			TypeName: "gauge",
+		},
+		{
+			Key:      "sizeTotal",
+			Name:     "unisphere_filesystem_total_size",
+			Desc:     "Size presented to hosts of the filesystem",
+			Unit:     "mb",
+			TypeName: "gauge",
+		},
+		{
+			Key:      "sizeUsed",
+			Name:     "unisphere_filesystem_used_size",
+			Desc:     "Used Size of the filesystem",
+			Unit:     "mb",
+			TypeName: "gauge",
+		},
+		{
+			Key:      "sizeAllocated",
+			Name:     "unisphere_filesystem_allocated_size",
+			Desc:     "Size of space actually allocated in the pool for the filesystem",
+			Unit:     "mb",
+			TypeName: "gauge",
+		},
+		{
+			Key:      "metadataSize",
+			Name:     "unisphere_filesystem_metadata_size",
+			Desc:     "Size of the filesystem metadata",
+			Unit:     "mb",
+			TypeName: "gauge",
+		},
+		{
+			Key:      "metadataSizeAllocated",
+			Name:     "unisphere_filesystem_metadata_allocated_size",
+			Desc:     "Size of pool space allocated to the filesystem metadata",
+			Unit:     "mb",
+			TypeName: "gauge",
+		},
+		{
+			Key:      "snapsSize",
+			Name:     "unisphere_filesystem_snaps_size",
+			Desc:     "Size of the snapshots of the filesystem",
+			Unit:     "mb",
+			TypeName: "gauge",
+		},
+		{
+			Key:      "snapsSizeAllocated",
+			Name:     "unisphere_filesystem_snaps_allocated_size",
+			Desc:     "Size of pool space allocated to the snapshots of the filesystem",
+			Unit:     "mb",
+			TypeName: "gauge",
+		},
+		{
+			Key:      "dataReductionSizeSaved",
+			Name:     "unisphere_filesystem_data_reduction_saved_size",
+			Desc:     "Size saved by data reduction of the filesystem",
+			Unit:     "mb",
+			TypeName: "gauge",
+		},
+		{
+			Key:      "dataReductionPercent",
+			Name:     "unisphere_filesystem_data_reduction_percent",
+			Desc:     "Data reduction percentage of the filesystem",
+			Unit:     "%",
+			TypeName: "gauge",
+		},
+		{
+			Key:      "dataReductionRatio",
+			Name:     "unisphere_filesystem_data_reduction_ratio",
+			Desc:     "Data reduction ratio of the filesystem",
+			Unit:     "",
+			TypeName: "gauge",
+		},
+	}
+	_m.opts = api.NewUnityActionOptions(string(api.UnityFilesystem))
+	_m.opts.Fields = []string{"id", "name", "supportedProtocols", "nasServer.id", "pool.id"}
+	for _, v := range _m.desc {
+		if v.Key == "info" {
+			continue
+		}
+		_m.opts.Fields = append(_m.opts.Fields, v.Key)
+	}
+}
+
+func (_m *ModuleFilesystem) SetConfig(inf interface{}) Module {
+	data, _ := json.Marshal(inf)
+	json.NewDecoder(bytes.NewReader(data)).Decode(&_m)
+	return _m
+}
+
+func (_m *ModuleFilesystem) IsEnabled() bool {
+	return isEnabled(_m.Enabled, _m.defaults)
+}
+
+func (_m *ModuleFilesystem) Run(logger *slog.Logger, col *Collector) {
+	meter := col.MeterProvider.Meter(_m.name)
+	client := col.Client
+
+	// Register Metrics...
+	var observableMap map[string]metric.Float64Observable
+	observableMap = CreateMapMetricDescriptor(meter, _m.desc, logger)
+
+	// Register Metrics for Observables...
+	var observableArray []metric.Observable
+	for _, obserable := range observableMap {
+		observableArray = append(observableArray, obserable)
+	}
+
+	// Callback
+	meter.RegisterCallback(func(ctx context.Context, observer metric.Observer) error {
+
+		// Set Attributes
+		if col.detectLabels == nil {
+			return nil
+		}
+		clientAttrs := metric.WithAttributes(append(col.customLabels, col.detectLabels...)...)
+
+		// Request Data
+		data, err := client.GetInstancesContext(ctx, _m.opts)
+		if err != nil {
+			col.handleError(logger, _m.name, err)
+			return nil
+		}
+		col.success = true
+
+		for _, v := range data {
+			// pool.id is attached to all metrics, so capacity rolls up by pool
+			fsAttrs := metric.WithAttributes(
+				attribute.String("filesystem.id", v.Get("id").String()),
+				attribute.String("filesystem.name", v.Get("name").String()),
+				attribute.String("pool.id", v.Get("pool.id").String()),
+				attribute.String("nasServer.id", v.Get("nasServer.id").String()),
+			)
+			for _, desc := range _m.desc {
+				key := desc.Key
+				var f float64
+				switch key {
+				case "info":
+					infoAttrs := metric.WithAttributes(
+						attribute.String("filesystem.protocol", filesystemProtocols[v.Get("supportedProtocols").Int()]),
+					)
+					observer.ObserveFloat64(observableMap[key], 1, clientAttrs, fsAttrs, infoAttrs)
+					continue
+				case "health.value", "dataReductionPercent", "dataReductionRatio":
+					f = v.Get(key).Float()
+				default:
+					f = utils.Bytes(v.Get(key).Int()).ToMiB()
+				}
+				observer.ObserveFloat64(observableMap[key], f, clientAttrs, fsAttrs)
+			}
+		}
+
+		return nil
+	}, observableArray...)
+
+}
@@ -0,0 +1,175 @@
+package collectors
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"unisphere_otel_provider/gounity/api"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+// fruType
+// metric's name of a field-replaceable unit, and the field of its parent enclosure ("" when it has no parent).
+type fruType struct {
+	metricName  string
+	parentField string
+}
+
+// fruTypes
+// field-replaceable units of Unisphere.
+var fruTypes = map[string]fruType{
+	"dae":          {metricName: "dae"},
+	"powerSupply":  {metricName: "power_supply", parentField: "parent"},
+	"fan":          {metricName: "fan", parentField: "parent"},
+	"battery":      {metricName: "battery", parentField: "parent"},
+	"lcc":          {metricName: "lcc", parentField: "parentDae"},
+	"memoryModule": {metricName: "memory_module", parentField: "parent"},
+	"ssc":          {metricName: "ssc", parentField: "parent"},
+	"ioModule":     {metricName: "io_module", parentField: "parent"},
+}
+
+func init() {
+	for key, t := range fruTypes {
+		registerModule(key, func() Module { return NewFRU(t.metricName, t.parentField) })
+	}
+}
+
+// ModuleFRU
+// scrape a type of field-replaceable unit (the module's key is the type of Unisphere).
+type ModuleFRU struct {
+	// Module's Information
+	name        string
+	metricName  string
+	parentField string
+	opts        *api.UnityActionOptions
+	desc        []*MetricDescriptor
+	defaults    bool
+
+	// Configuration File
+	Enabled *bool `yaml:"enabled"`
+}
+
+func NewFRU(metricName string, parentField string) *ModuleFRU {
+	return &ModuleFRU{
+		metricName:  metricName,
+		parentField: parentField,
+		defaults:    false,
+	}
+}
+
+func (_m *ModuleFRU) Init(key string) {
+	_m.name = key
+	_m.desc = []*MetricDescriptor{
+		{
+			Key:      "health.value",
+			Name:     "unisphere_" + _m.metricName + "_health",
+			Desc:     "Health of the associated resource",
+			Unit:     "",
+			TypeName: "gauge",
+		},
+		{
+			Key:      "needsReplacement",
+			Name:     "unisphere_" + _m.metricName + "_needs_replacement",
+			Desc:     "Indicates whether the unit needs replacement",
+			Unit:     "",
+			TypeName: "gauge",
+		},
+		{
+			Key:      "present",
+			Name:     "unisphere_" + _m.metricName + "_present",
+			Desc:     "Indicates whether the unit reports its health (not Unknown)",
+			Unit:     "",
+			TypeName: "gauge",
+		},
+	}
+	_m.opts = api.NewUnityActionOptions(key)
+	_m.opts.Fields = []string{
+		"id",
+		"name",
+		"slotNumber",
+		"health.value",
+		"needsReplacement",
+	}
+	if _m.parentField != "" {
+		_m.opts.Fields = append(_m.opts.Fields, _m.parentField+".id")
+	}
+}
+
+func (_m *ModuleFRU) SetConfig(inf interface{}) Module {
+	data, _ := json.Marshal(inf)
+	json.NewDecoder(bytes.NewReader(data)).Decode(&_m)
+	return _m
+}
+
+func (_m *ModuleFRU) IsEnabled() bool {
+	return isEnabled(_m.Enabled, _m.defaults)
+}
+
+func (_m *ModuleFRU) Run(logger *slog.Logger, col *Collector) {
+	meter := col.MeterProvider.Meter(_m.name)
+	client := col.Client
+
+	// Register Metrics...
+	var observableMap map[string]metric.Float64Observable
+	observableMap = CreateMapMetricDescriptor(meter, _m.desc, logger)
+
+	// Register Metrics for Observables...
+	var observableArray []metric.Observable
+	for _, obserable := range observableMap {
+		observableArray = append(observableArray, obserable)
+	}
+
+	// Callback
+	meter.RegisterCallback(func(ctx context.Context, observer metric.Observer) error {
+
+		// Set Attributes
+		if col.detectLabels == nil {
+			return nil
+		}
+		clientAttrs := metric.WithAttributes(append(col.customLabels, col.detectLabels...)...)
+
+		// Request Data
+		data, err := client.GetInstancesContext(ctx, _m.opts)
+		if err != nil {
+			col.handleError(logger, _m.name, err)
+			return nil
+		}
+		col.success = true
+
+		for _, v := range data {
+			attrs := []attribute.KeyValue{
+				attribute.String(_m.name+".id", v.Get("id").String()),
+				attribute.String(_m.name+".name", v.Get("name").String()),
+				attribute.String("slot.id", v.Get("slotNumber").String()),
+			}
+			if _m.parentField != "" {
+				attrs = append(attrs, attribute.String("parent.id", v.Get(_m.parentField+".id").String()))
+			}
+			fruAttrs := metric.WithAttributes(attrs...)
+			for _, desc := range _m.desc {
+				key := desc.Key
+				var f float64
+				switch key {
+				case "needsReplacement":
+					if v.Get(key).Bool() {
+						f = 1
+					}
+				case "present":
+					// A removed unit is still listed, and its health is Unknown (0).
+					if v.Get("health.value").Int() != 0 {
+						f = 1
+					}
+				default:
+					f = v.Get(key).Float()
+				}
+				observer.ObserveFloat64(observableMap[key], f, clientAttrs, fruAttrs)
+			}
+		}
+
+		return nil
+	}, observableArray...)
+
+}
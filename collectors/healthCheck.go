@@ -6,12 +6,13 @@ import (
 	"encoding/json"
 	"log/slog"
 
+	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/metric"
 )
 
 func init() {
 	key := "healthCheck"
-	registerModule(key, NewHealth())
+	registerModule(key, func() Module { return NewHealth() })
 
 }
 
@@ -19,6 +20,9 @@ type ModuleHealth struct {
 	name     string
 	defaults bool
 	desc     []*MetricDescriptor
+
+	// Configuration File
+	Enabled *bool `yaml:"enabled"`
 }
 
 func NewHealth() *ModuleHealth {
@@ -33,6 +37,10 @@ func (_m *ModuleHealth) SetConfig(inf interface{}) Module {
 	return _m
 }
 
+func (_m *ModuleHealth) IsEnabled() bool {
+	return isEnabled(_m.Enabled, _m.defaults)
+}
+
 func (_m *ModuleHealth) Init(key string) {
 	_m.name = key
 	_m.desc = []*MetricDescriptor{
@@ -43,6 +51,20 @@ func (_m *ModuleHealth) Init(key string) {
 			Unit:     "",
 			TypeName: "gauge",
 		},
+		{
+			Key:      "breaker",
+			Name:     "unisphere_circuit_breaker_state",
+			Desc:     "state of the circuit breaker to unisphere (0: closed, 1: half-open, 2: open)",
+			Unit:     "",
+			TypeName: "gauge",
+		},
+		{
+			Key:      "errors",
+			Name:     "unisphere_scrape_errors",
+			Desc:     "count of failed requests to unisphere by module and error type",
+			Unit:     "",
+			TypeName: "counter",
+		},
 	}
 }
 
@@ -69,6 +91,15 @@ func (_m *ModuleHealth) Run(logger *slog.Logger, col *Collector) {
 			health = 0
 		}
 		observer.ObserveFloat64(observableMap["up"], health, clientAttrs)
+		observer.ObserveFloat64(observableMap["breaker"], float64(col.Client.BreakerState()), clientAttrs)
+
+		for k, v := range col.errorCounts() {
+			errAttrs := metric.WithAttributes(
+				attribute.String("module", k.module),
+				attribute.String("error.type", k.errorType),
+			)
+			observer.ObserveFloat64(observableMap["errors"], float64(v), clientAttrs, errAttrs)
+		}
 		return nil
 	}, observableArray...)
 }
@@ -15,7 +15,7 @@ import (
 
 func init() {
 	key := "host"
-	registerModule(key, NewHost())
+	registerModule(key, func() Module { return NewHost() })
 }
 
 type ModuleHost struct {
@@ -108,6 +108,10 @@ func (_m *ModuleHost) SetConfig(inf interface{}) Module {
 	return _m
 }
 
+func (_m *ModuleHost) IsEnabled() bool {
+	return isEnabled(_m.Enabled, _m.defaults)
+}
+
 func (_m *ModuleHost) Run(logger *slog.Logger, col *Collector) {
 	meter := col.MeterProvider.Meter(_m.name)
 	client := col.Client
@@ -132,10 +136,9 @@ func (_m *ModuleHost) Run(logger *slog.Logger, col *Collector) {
 		clientAttrs := metric.WithAttributes(append(col.customLabels, col.detectLabels...)...)
 
 		// Request Data
-		data, err := client.GetInstances(_m.opts)
+		data, err := client.GetInstancesContext(ctx, _m.opts)
 		if err != nil {
-			logger.Error("Failed to get", "error", err, "module", _m.name)
-			col.success = false
+			col.handleError(logger, _m.name, err)
 			return nil
 		}
 		col.success = true
@@ -0,0 +1,155 @@
+package collectors
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"unisphere_otel_provider/gounity/api"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+func init() {
+	key := "iscsiPortal"
+	registerModule(key, func() Module { return NewIscsiPortal() })
+}
+
+// ModuleIscsiPortal
+// scrape iscsiPortal with its iscsiNode and ethernetPort.
+// the port is exported as `fePort`, the same as initiator paths of the host module.
+type ModuleIscsiPortal struct {
+	// Module's Information
+	name     string
+	opts     *api.UnityActionOptions
+	desc     []*MetricDescriptor
+	defaults bool
+
+	// Configuration File
+	Enabled *bool `yaml:"enabled"`
+}
+
+func NewIscsiPortal() *ModuleIscsiPortal {
+	return &ModuleIscsiPortal{
+		defaults: false,
+	}
+}
+
+func (_m *ModuleIscsiPortal) Init(key string) {
+	_m.name = key
+	_m.desc = []*MetricDescriptor{
+		{
+			Key:      "info",
+			Name:     "unisphere_iscsi_portal_info",
+			Desc:     "information of the associated resource",
+			Unit:     "",
+			TypeName: "gauge",
+		},
+		{
+			Key:      "ethernetPort.health.value",
+			Name:     "unisphere_iscsi_portal_health",
+			Desc:     "Health of the ethernet port of the portal",
+			Unit:     "",
+			TypeName: "gauge",
+		},
+		{
+			Key:      "ethernetPort.mtu",
+			Name:     "unisphere_iscsi_portal_mtu",
+			Desc:     "MTU of the ethernet port of the portal",
+			Unit:     "",
+			TypeName: "gauge",
+		},
+	}
+	_m.opts = api.NewUnityActionOptions(string(api.UnityIscsiPortal))
+	_m.opts.Fields = []string{
+		"id",
+		"ipAddress",
+		"netmask",
+		"v6PrefixLength",
+		"gateway",
+		"vlanId",
+		"iscsiNode.id",
+		"iscsiNode.name",
+		"iscsiNode.alias",
+		"ethernetPort.id",
+		"ethernetPort.health.value",
+		"ethernetPort.mtu",
+	}
+}
+
+func (_m *ModuleIscsiPortal) SetConfig(inf interface{}) Module {
+	data, _ := json.Marshal(inf)
+	json.NewDecoder(bytes.NewReader(data)).Decode(&_m)
+	return _m
+}
+
+func (_m *ModuleIscsiPortal) IsEnabled() bool {
+	return isEnabled(_m.Enabled, _m.defaults)
+}
+
+func (_m *ModuleIscsiPortal) Run(logger *slog.Logger, col *Collector) {
+	meter := col.MeterProvider.Meter(_m.name)
+	client := col.Client
+
+	// Register Metrics...
+	var observableMap map[string]metric.Float64Observable
+	observableMap = CreateMapMetricDescriptor(meter, _m.desc, logger)
+
+	// Register Metrics for Observables...
+	var observableArray []metric.Observable
+	for _, obserable := range observableMap {
+		observableArray = append(observableArray, obserable)
+	}
+
+	// Callback
+	meter.RegisterCallback(func(ctx context.Context, observer metric.Observer) error {
+
+		// Set Attributes
+		if col.detectLabels == nil {
+			return nil
+		}
+		clientAttrs := metric.WithAttributes(append(col.customLabels, col.detectLabels...)...)
+
+		// Request Data
+		data, err := client.GetInstancesContext(ctx, _m.opts)
+		if err != nil {
+			col.handleError(logger, _m.name, err)
+			return nil
+		}
+		col.success = true
+
+		for _, v := range data {
+			portalAttrs := metric.WithAttributes(
+				attribute.String("iscsiPortal.id", v.Get("id").String()),
+				attribute.String("fePort", v.Get("ethernetPort.id").String()),
+			)
+			for _, desc := range _m.desc {
+				key := desc.Key
+				switch key {
+				case "info":
+					// IPv6 portal has prefix length instead of netmask
+					netmask := v.Get("netmask").String()
+					if netmask == "" && v.Get("v6PrefixLength").Exists() {
+						netmask = "/" + v.Get("v6PrefixLength").String()
+					}
+					infoAttrs := metric.WithAttributes(
+						attribute.String("portal.ip", v.Get("ipAddress").String()),
+						attribute.String("portal.netmask", netmask),
+						attribute.String("portal.gateway", v.Get("gateway").String()),
+						attribute.String("portal.vlan", v.Get("vlanId").String()),
+						attribute.String("iscsiNode.id", v.Get("iscsiNode.id").String()),
+						attribute.String("iscsiNode.alias", v.Get("iscsiNode.alias").String()),
+						attribute.String("iscsi.iqn", v.Get("iscsiNode.name").String()),
+					)
+					observer.ObserveFloat64(observableMap[key], 1, clientAttrs, portalAttrs, infoAttrs)
+				default:
+					observer.ObserveFloat64(observableMap[key], v.Get(key).Float(), clientAttrs, portalAttrs)
+				}
+			}
+		}
+
+		return nil
+	}, observableArray...)
+
+}
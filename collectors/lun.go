@@ -15,7 +15,7 @@ import (
 
 func init() {
 	key := "lun"
-	registerModule(key, NewLun())
+	registerModule(key, func() Module { return NewLun() })
 }
 
 type ModuleLun struct {
@@ -81,6 +81,10 @@ func (_m *ModuleLun) SetConfig(inf interface{}) Module {
 	return _m
 }
 
+func (_m *ModuleLun) IsEnabled() bool {
+	return isEnabled(_m.Enabled, _m.defaults)
+}
+
 func (_pv *ModuleLun) Run(logger *slog.Logger, col *Collector) {
 	meter := col.MeterProvider.Meter(_pv.name)
 	client := col.Client
@@ -105,10 +109,9 @@ func (_pv *ModuleLun) Run(logger *slog.Logger, col *Collector) {
 		clientAttrs := metric.WithAttributes(append(col.customLabels, col.detectLabels...)...)
 
 		// Request Data
-		data, err := client.GetInstances(_pv.opts)
+		data, err := client.GetInstancesContext(ctx, _pv.opts)
 		if err != nil {
-			logger.Error("Failed to get", "error", err, "module", _pv.name)
-			col.success = false
+			col.handleError(logger, _pv.name, err)
 			return nil
 		}
 		col.success = true
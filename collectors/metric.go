@@ -4,8 +4,12 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"log/slog"
 	"strings"
+	"sync"
+	"time"
+	"unisphere_otel_provider/gounity"
 	"unisphere_otel_provider/gounity/api"
 	"unisphere_otel_provider/utils"
 
@@ -19,15 +23,32 @@ type ModuleMetric struct {
 	name     string
 	defaults bool
 	desc     []*MetricDescriptor
+	mu       sync.Mutex
+	shards   []*metricQueryShard
+	closed   bool // Queries are deleted on shutdown, and not created again
 
 	// Configuration File
 	Enabled *bool    `yaml:"enabled"`
 	Paths   []string `yaml:"paths"`
 }
 
+// metricQueryMaxPaths
+// maximum paths of a metric realtime query.
+const metricQueryMaxPaths = 48
+
+// metricQueryShard
+// a metric realtime query with a part of paths.
+type metricQueryShard struct {
+	paths      []string
+	qid        string
+	expiration time.Time
+	newest     time.Time // Newest timestamp of results, by the array's clock
+	progressed time.Time // When newest was advanced (or the query was created), by the local clock
+}
+
 func init() {
 	key := "metric"
-	registerModule(key, NewMetric())
+	registerModule(key, func() Module { return NewMetric() })
 }
 
 func NewMetric() *ModuleMetric {
@@ -46,6 +67,10 @@ func (_m *ModuleMetric) SetConfig(inf interface{}) Module {
 	return _m
 }
 
+func (_m *ModuleMetric) IsEnabled() bool {
+	return isEnabled(_m.Enabled, _m.defaults)
+}
+
 func (_m *ModuleMetric) Run(logger *slog.Logger, col *Collector) {
 	meter := col.MeterProvider.Meter(_m.name)
 	client := col.Client
@@ -56,7 +81,7 @@ func (_m *ModuleMetric) Run(logger *slog.Logger, col *Collector) {
 	descOpts.Filters = []string{
 		"isRealtimeAvailable eq true",
 	}
-	descData, err := client.GetInstances(descOpts)
+	descData, err := client.GetInstancesContext(col.ctx, descOpts)
 	if err != nil {
 		logger.Warn("cannot get metric values", "err", err)
 		return
@@ -64,44 +89,24 @@ func (_m *ModuleMetric) Run(logger *slog.Logger, col *Collector) {
 
 	// Create Metric Descriptions...
 	var metricPaths []string
+	seen := make(map[string]bool)
 	for _, v := range descData {
 		for _, path := range _m.Paths {
-			var match bool
-			// When last char is '%', remove it and find contain from metrics
-			// others are find match.
-			if string(path[len(path)-1]) == "%" {
-				pattern := strings.Replace(path, "%", "", -1)
-				match = strings.Contains(v.Get("path").String(), pattern)
-			} else {
-				if path == v.Get("path").String() {
-					match = true
-				}
-			}
-			if match {
-				var mType string
-				switch v.Get("type").Int() {
-				case 2:
-					mType = "counter"
-				case 3:
-					mType = "counter"
-				case 4:
-					mType = "gauge"
-				case 5:
-					mType = "gauge"
-				case 6:
+			if matchMetricPath(path, v.Get("path").String()) {
+				mType := metricTypeName(v.Get("type").Int())
+				if mType == "" {
 					logger.Info("SKIP THIS METRIC: this metric is not output number", "module", _m.name, "path", path)
 					continue
-				case 7:
-					mType = "counter"
-				case 8:
-					mType = "counter"
 				}
-				tmp := "unisphere_" + strings.Replace(strings.ToLower(v.Get("path").String()), ".*.", "_", -1)
-
+				// Skip the path matched by other patterns
+				if seen[v.Get("path").String()] {
+					continue
+				}
+				seen[v.Get("path").String()] = true
 				metricPaths = append(metricPaths, v.Get("path").String())
 				_m.desc = append(_m.desc, &MetricDescriptor{
 					Key:      v.Get("path").String(),
-					Name:     strings.Replace(tmp, ".", "_", -1),
+					Name:     metricName(v.Get("path").String()),
 					Desc:     v.Get("description").String(),
 					Unit:     strings.ToLower(v.Get("unitDisplayString").String()),
 					TypeName: mType,
@@ -121,65 +126,94 @@ func (_m *ModuleMetric) Run(logger *slog.Logger, col *Collector) {
 	}
 
 	// Metric Realtime Query Maximum Paths == 48
-	if len(observableArray) > 48 {
-		logger.Error("Too Many Paths", "provider", _m.name, "path_count", len(metricPaths))
-		return
+	// Partition paths into multiple queries
+	_m.mu.Lock()
+	for i := 0; i < len(metricPaths); i += metricQueryMaxPaths {
+		end := i + metricQueryMaxPaths
+		if end > len(metricPaths) {
+			end = len(metricPaths)
+		}
+		_m.shards = append(_m.shards, &metricQueryShard{paths: metricPaths[i:end]})
 	}
-	createQidOpts := api.NewUnityActionOptions(string(api.UnityMetricRealTimeQuery))
-	logger.Info("Create Metric Query", "provider", _m.name, "path_count", len(metricPaths))
+	logger.Info("Create Metric Query", "provider", _m.name, "path_count", len(metricPaths), "query_count", len(_m.shards))
 
-	// Get Query ID
-	var qid string
-	if qid, err = client.PostMetricRealTimeQuery(createQidOpts, _m.Paths, col.interval); err != nil {
-		logger.Warn("cannot create metric", "err", err)
-		return
-	} else if qid == "" {
-		logger.Warn("cannot get query id", "err", "qid is empty")
+	// Get Query IDs
+	for _, shard := range _m.shards {
+		if _m.closed {
+			break
+		}
+		if err = _m.createQuery(col.ctx, col, shard); err != nil {
+			logger.Warn("cannot create metric", "err", err)
+		}
 	}
+	_m.mu.Unlock()
 
 	//// Callback
 	meter.RegisterCallback(func(ctx context.Context, observer metric.Observer) error {
+		clientAttrs := metric.WithAttributes(append(col.customLabels, col.detectLabels...)...)
 
-		if qid == "" {
-			logger.Info("Recreate the Metric Realtime Query", "provider", _m.name, "path_count", len(metricPaths))
-			if qid, err = client.PostMetricRealTimeQuery(createQidOpts, _m.Paths, col.interval); err != nil {
-				logger.Warn("cannot create metric", "err", err)
-				return nil
-			}
+		_m.mu.Lock()
+		defer _m.mu.Unlock()
+		if _m.closed {
+			return nil
 		}
 
-		// Set Attributes
-		//if col.labels == nil {
-		//	logger.Debug("hostLabels not set")
-		//	return nil
-		//}
-		clientAttrs := metric.WithAttributes(append(col.customLabels, col.detectLabels...)...)
-
-		// Request Data
-		opts := api.NewUnityActionOptions("metricQueryResult")
-		opts.Filters = []string{"queryId eq " + qid}
+		// Request Data of each query, and merge them
 		var data []gjson.Result
-		data, err = client.GetInstances(opts)
-		if err != nil {
-			logger.Error("Failed to get metric", "error", err)
-			col.success = false
-			qid = ""
-			return nil
+		var failed bool
+		for _, shard := range _m.shards {
+			// Refresh the query before it expires
+			if shard.qid != "" && !shard.expiration.IsZero() && time.Until(shard.expiration) < 2*col.interval {
+				logger.Info("Refresh the expiring Metric Realtime Query", "provider", _m.name, "query_id", shard.qid, "expiration", shard.expiration)
+				if err := _m.deleteQuery(ctx, col, shard); err != nil {
+					logger.Debug("cannot delete metric query", "err", err, "query_id", shard.qid)
+				}
+				shard.qid = ""
+			}
+
+			// Recreate only the expired query
+			if shard.qid == "" {
+				logger.Info("Recreate the Metric Realtime Query", "provider", _m.name, "path_count", len(shard.paths))
+				if err := _m.createQuery(ctx, col, shard); err != nil {
+					col.handleError(logger, _m.name, err)
+					failed = true
+					continue
+				}
+			}
+
+			opts := api.NewUnityActionOptions(string(api.UnityMetricQueryResult))
+			opts.Filters = []string{"queryId eq " + shard.qid}
+			result, err := client.GetInstancesContext(ctx, opts)
+			if err != nil {
+				col.handleError(logger, _m.name, err)
+				if !gounity.IsNotFound(err) {
+					_m.deleteQuery(ctx, col, shard)
+				}
+				shard.qid = ""
+				failed = true
+				continue
+			}
+
+			// Detect the stale query, whose newest result is not advanced, and drop its results
+			if _m.isStale(col, shard, result) {
+				logger.Info("Metric Realtime Query is stale", "provider", _m.name, "query_id", shard.qid)
+				_m.deleteQuery(ctx, col, shard)
+				shard.qid = ""
+				col.success = false
+				failed = true
+				continue
+			}
+			data = append(data, result...)
+		}
+		if !failed {
+			col.success = true
 		}
-		col.success = true
 
 		// Parsing Metric &
 		for _, content := range data {
 			// Create Label Name
 			var key = content.Get("path").String()
-			var labelKeys []string
-			var preString string
-			for _, v := range strings.Split(key, ".") {
-				if v == "*" {
-					labelKeys = append(labelKeys, preString)
-				}
-				preString = v
-			}
+			labelKeys := metricLabelKeys(key)
 
 			// Get Values...
 			result := utils.ParseMetric(content.Get("values"))
@@ -195,3 +229,118 @@ func (_m *ModuleMetric) Run(logger *slog.Logger, col *Collector) {
 		return nil
 	}, observableArray...)
 }
+
+// matchMetricPath
+// When last char of the pattern is '%', remove it and find contain from metrics
+// others are find match.
+func matchMetricPath(pattern string, path string) bool {
+	if strings.HasSuffix(pattern, "%") {
+		return strings.Contains(path, strings.Replace(pattern, "%", "", -1))
+	}
+	return pattern == path
+}
+
+// metricTypeName
+// metric type of Unity's metric type, empty when it is not output number.
+func metricTypeName(t int64) string {
+	switch t {
+	case 2, 3, 7, 8:
+		return "counter"
+	case 4, 5:
+		return "gauge"
+	}
+	return ""
+}
+
+// metricName
+// metric name of the path, e.g. sp.*.cpu.summary.busyTicks -> unisphere_sp_cpu_summary_busyticks
+func metricName(path string) string {
+	tmp := "unisphere_" + strings.Replace(strings.ToLower(path), ".*.", "_", -1)
+	return strings.Replace(tmp, ".", "_", -1)
+}
+
+// metricLabelKeys
+// label names of the path's wildcards, the label is the previous element of '*'.
+// e.g. sp.*.physical.disk.*.reads -> [sp, disk]
+func metricLabelKeys(path string) []string {
+	var labelKeys []string
+	var preString string
+	for _, v := range strings.Split(path, ".") {
+		if v == "*" {
+			labelKeys = append(labelKeys, preString)
+		}
+		preString = v
+	}
+	return labelKeys
+}
+
+// createQuery
+// create the metric realtime query of the shard, and read its expiration.
+// the expiration is set by the array on creation, and the query has no action to extend it,
+// so the query is refreshed by deleting and creating it again before it expires.
+func (_m *ModuleMetric) createQuery(ctx context.Context, col *Collector, shard *metricQueryShard) error {
+	opts := api.NewUnityActionOptions(string(api.UnityMetricRealTimeQuery))
+	qid, err := col.Client.PostMetricRealTimeQueryContext(ctx, opts, shard.paths, col.interval)
+	if err != nil {
+		return err
+	}
+	if qid == "" {
+		return errors.New("qid is empty")
+	}
+	shard.qid = qid
+	shard.expiration = time.Time{}
+	shard.newest = time.Time{}
+	shard.progressed = time.Now()
+
+	// Expiration is optional, the stale query is detected by results
+	opts.WithId(qid)
+	opts.Fields = []string{"expiration"}
+	if content, err := col.Client.GetInstanceContext(ctx, opts); err == nil && content.Get("expiration").Exists() {
+		shard.expiration = content.Get("expiration").Time()
+	}
+	return nil
+}
+
+// deleteQuery
+// delete the metric realtime query of the shard on the array.
+func (_m *ModuleMetric) deleteQuery(ctx context.Context, col *Collector, shard *metricQueryShard) error {
+	if shard.qid == "" {
+		return nil
+	}
+	opts := api.NewUnityActionOptions(string(api.UnityMetricRealTimeQuery))
+	opts.WithId(shard.qid)
+	err := col.Client.DeleteInstanceContext(ctx, opts)
+	if gounity.IsNotFound(err) {
+		return nil
+	}
+	return err
+}
+
+// isStale
+// the query is stale when the newest timestamp of results is not advanced for 3 intervals
+// (including the grace period of the first samples).
+// timestamps of the array are compared only with each other, so the clock skew to the array does not matter.
+func (_m *ModuleMetric) isStale(col *Collector, shard *metricQueryShard, result []gjson.Result) bool {
+	for _, v := range result {
+		if t := v.Get("timestamp").Time(); t.After(shard.newest) {
+			shard.newest = t
+			shard.progressed = time.Now()
+		}
+	}
+	return time.Since(shard.progressed) > 3*col.interval
+}
+
+// Close
+// delete metric realtime queries on the array, and stop creating them again.
+func (_m *ModuleMetric) Close(logger *slog.Logger, col *Collector) {
+	_m.mu.Lock()
+	defer _m.mu.Unlock()
+	_m.closed = true
+	for _, shard := range _m.shards {
+		if err := _m.deleteQuery(col.ctx, col, shard); err != nil {
+			logger.Warn("cannot delete metric query", "err", err, "query_id", shard.qid)
+			continue
+		}
+		shard.qid = ""
+	}
+}
@@ -0,0 +1,232 @@
+package collectors
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unisphere_otel_provider/gounity/api"
+	"unisphere_otel_provider/utils"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/sdk/instrumentation"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+func init() {
+	key := "metricHistory"
+	registerModule(key, func() Module { return NewMetricHistory() })
+}
+
+// ModuleMetricHistory
+// collect historical metric values, and export them with their original timestamps.
+// values since the last collected timestamp are backfilled (bounded by Backfill),
+// so there is no hole after the provider or the array was down.
+// the last collected timestamps are saved per path in the state file, so a restart does not export them again.
+type ModuleMetricHistory struct {
+	// Module's Information
+	name     string
+	opts     *api.UnityActionOptions
+	desc     map[string]*MetricDescriptor
+	defaults bool
+	cursors  map[string]time.Time
+
+	// Configuration File
+	Enabled  *bool    `yaml:"enabled"`
+	Paths    []string `yaml:"paths"`
+	Interval int64    `yaml:"interval"` // Unity's sampling interval in seconds (60, 300, 3600, 14400)
+	Backfill string   `yaml:"backfill"` // Window to backfill at startup or after an outage
+}
+
+func NewMetricHistory() *ModuleMetricHistory {
+	return &ModuleMetricHistory{
+		defaults: false,
+		Interval: 60,
+		Backfill: "1h",
+	}
+}
+
+func (_m *ModuleMetricHistory) Init(key string) {
+	_m.name = key
+	_m.desc = make(map[string]*MetricDescriptor)
+	_m.cursors = make(map[string]time.Time)
+	_m.opts = api.NewUnityActionOptions(string(api.UnityMetricValue))
+	_m.opts.Fields = []string{"path", "timestamp", "interval", "values"}
+}
+
+func (_m *ModuleMetricHistory) SetConfig(inf interface{}) Module {
+	data, _ := json.Marshal(inf)
+	json.NewDecoder(bytes.NewReader(data)).Decode(&_m)
+	return _m
+}
+
+func (_m *ModuleMetricHistory) IsEnabled() bool {
+	return isEnabled(_m.Enabled, _m.defaults)
+}
+
+// metricHistoryName
+// metric name of the path with the history prefix, e.g. sp.*.cpu.summary.utilization -> unisphere_history_sp_cpu_summary_utilization
+// so it does not collide with the realtime metric of the same path, which may be a counter.
+func metricHistoryName(path string) string {
+	return "unisphere_history_" + strings.TrimPrefix(metricName(path), "unisphere_")
+}
+
+// stateKey
+// module key of the path's cursor in the state file.
+func (_m *ModuleMetricHistory) stateKey(path string) string {
+	return _m.name + "/" + path
+}
+
+func (_m *ModuleMetricHistory) Run(logger *slog.Logger, col *Collector) {
+	client := col.Client
+	if col.MetricExporter == nil {
+		logger.Warn("metric exporter is not set", "module", _m.name)
+		return
+	}
+	backfill, err := time.ParseDuration(_m.Backfill)
+	if err != nil {
+		logger.Warn("invalid backfill, use default", "module", _m.name, "backfill", _m.Backfill, "err", err)
+		backfill = time.Hour
+	}
+
+	// Get Metric List...
+	descOpts := api.NewUnityActionOptions(string(api.UnityMetric))
+	descOpts.Fields = []string{"name", "path", "type", "unitDisplayString", "description"}
+	descOpts.Filters = []string{
+		"isHistoricalAvailable eq true",
+	}
+	descData, err := client.GetInstancesContext(col.ctx, descOpts)
+	if err != nil {
+		logger.Warn("cannot get metric values", "err", err)
+		return
+	}
+
+	// Create Metric Descriptions...
+	var metricPaths []string
+	for _, v := range descData {
+		for _, path := range _m.Paths {
+			if !matchMetricPath(path, v.Get("path").String()) || _m.desc[v.Get("path").String()] != nil {
+				continue
+			}
+			if metricTypeName(v.Get("type").Int()) == "" {
+				logger.Info("SKIP THIS METRIC: this metric is not output number", "module", _m.name, "path", path)
+				continue
+			}
+			metricPaths = append(metricPaths, v.Get("path").String())
+			_m.desc[v.Get("path").String()] = &MetricDescriptor{
+				Key:      v.Get("path").String(),
+				Name:     metricHistoryName(v.Get("path").String()),
+				Desc:     v.Get("description").String(),
+				Unit:     strings.ToLower(v.Get("unitDisplayString").String()),
+				TypeName: "gauge",
+			}
+		}
+	}
+	logger.Info("Collect Metric History", "provider", _m.name, "path_count", len(metricPaths), "interval", _m.Interval, "backfill", backfill)
+
+	// Resume from saved cursors...
+	for _, path := range metricPaths {
+		if c, ok := col.State.Cursor(col.Instance, _m.stateKey(path)); ok {
+			_m.cursors[path] = c.Timestamp
+		}
+	}
+
+	for {
+		// Same attributes of data points as other collectors
+		clientAttrs := append(col.customLabels, col.detectLabels...)
+		var metrics []metricdata.Metrics
+		var failed bool
+		cursors := make(map[string]time.Time)
+		for _, path := range metricPaths {
+			// Resume from the last collected timestamp, bounded by backfill window
+			since := time.Now().Add(-backfill)
+			if cursor, ok := _m.cursors[path]; ok && cursor.After(since) {
+				since = cursor
+			}
+
+			opt := *_m.opts
+			opt.Filters = []string{
+				"path eq \"" + path + "\" and interval eq " + strconv.FormatInt(_m.Interval, 10) +
+					" and timestamp gt \"" + since.UTC().Format("2006-01-02T15:04:05.000Z") + "\"",
+			}
+			data, err := client.GetInstancesContext(col.ctx, &opt)
+			if err != nil {
+				col.handleError(logger, _m.name, err)
+				failed = true
+				continue
+			}
+
+			labelKeys := metricLabelKeys(path)
+			var dataPoints []metricdata.DataPoint[float64]
+			for _, content := range data {
+				timestamp := content.Get("timestamp").Time()
+				if !timestamp.After(since) {
+					continue
+				}
+				for _, r := range utils.ParseMetric(content.Get("values")) {
+					attrs := append([]attribute.KeyValue{}, clientAttrs...)
+					for i, lname := range labelKeys {
+						attrs = append(attrs, attribute.String(lname, r.Labels[i]))
+					}
+					dataPoints = append(dataPoints, metricdata.DataPoint[float64]{
+						Attributes: attribute.NewSet(attrs...),
+						Time:       timestamp,
+						Value:      r.Value.Float(),
+					})
+				}
+				if timestamp.After(cursors[path]) {
+					cursors[path] = timestamp
+				}
+			}
+			if len(dataPoints) == 0 {
+				continue
+			}
+			sort.Slice(dataPoints, func(i, j int) bool { return dataPoints[i].Time.Before(dataPoints[j].Time) })
+
+			desc := _m.desc[path]
+			metrics = append(metrics, metricdata.Metrics{
+				Name:        desc.Name,
+				Description: desc.Desc,
+				Unit:        desc.Unit,
+				Data:        metricdata.Gauge[float64]{DataPoints: dataPoints},
+			})
+		}
+		if !failed {
+			col.success = true
+		}
+
+		// Export with original timestamps
+		// move cursors only when exported, to retry the same window on failure
+		exported := true
+		if len(metrics) > 0 {
+			rm := &metricdata.ResourceMetrics{
+				Resource: col.Resource,
+				ScopeMetrics: []metricdata.ScopeMetrics{
+					{
+						Scope:   instrumentation.Scope{Name: _m.name},
+						Metrics: metrics,
+					},
+				},
+			}
+			if err := col.MetricExporter.Export(col.ctx, rm); err != nil {
+				logger.Error("cannot export metric history", "module", _m.name, "err", err)
+				exported = false
+			}
+		}
+		if exported {
+			saved := make(map[string]Cursor)
+			for path, cursor := range cursors {
+				_m.cursors[path] = cursor
+				saved[_m.stateKey(path)] = Cursor{Timestamp: cursor}
+			}
+			if err := col.State.SetCursors(col.Instance, saved); err != nil {
+				logger.Warn("cannot save state", "module", _m.name, "err", err)
+			}
+		}
+
+		time.Sleep(col.interval)
+	}
+}
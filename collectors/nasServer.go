@@ -0,0 +1,223 @@
+package collectors
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"unisphere_otel_provider/gounity/api"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+func init() {
+	key := "nasServer"
+	registerModule(key, func() Module { return NewNasServer() })
+}
+
+// ModuleNasServer
+// scrape nasServer and its fileInterface.
+type ModuleNasServer struct {
+	// Module's Information
+	name      string
+	opts      *api.UnityActionOptions
+	ifaceOpts *api.UnityActionOptions
+	desc      []*MetricDescriptor
+	defaults  bool
+
+	// Configuration File
+	Enabled *bool `yaml:"enabled"`
+}
+
+func NewNasServer() *ModuleNasServer {
+	return &ModuleNasServer{
+		defaults: false,
+	}
+}
+
+func (_m *ModuleNasServer) Init(key string) {
+	_m.name = key
+	_m.desc = []*MetricDescriptor{
+		{
+			Key:      "info",
+			Name:     "unisphere_nas_server_info",
+			Desc:     "information of the associated resource",
+			Unit:     "",
+			TypeName: "gauge",
+		},
+		{
+			Key:      "health.value",
+			Name:     "unisphere_nas_server_health",
+			Desc:     "Health of the associated resource",
+			Unit:     "",
+			TypeName: "gauge",
+		},
+		{
+			Key:      "failover",
+			Name:     "unisphere_nas_server_failover",
+			Desc:     "Indicates whether the NAS server is running on a storage processor other than its home",
+			Unit:     "",
+			TypeName: "gauge",
+		},
+		{
+			Key:      "isReplicationDestination",
+			Name:     "unisphere_nas_server_replication_destination",
+			Desc:     "Indicates whether the NAS server is a replication destination",
+			Unit:     "",
+			TypeName: "gauge",
+		},
+		{
+			Key:      "cifsServer",
+			Name:     "unisphere_nas_server_cifs_enabled",
+			Desc:     "Indicates whether a CIFS server is configured on the NAS server",
+			Unit:     "",
+			TypeName: "gauge",
+		},
+		{
+			Key:      "nfsServer",
+			Name:     "unisphere_nas_server_nfs_enabled",
+			Desc:     "Indicates whether NFSv3 or NFSv4 is enabled on the NAS server",
+			Unit:     "",
+			TypeName: "gauge",
+		},
+		// File Interface's Metrics...
+		{
+			Key:      "interface.info",
+			Name:     "unisphere_nas_server_interface_info",
+			Desc:     "information of the file interface",
+			Unit:     "",
+			TypeName: "gauge",
+		},
+	}
+	_m.opts = api.NewUnityActionOptions(string(api.UnityNasServer))
+	_m.opts.Fields = []string{
+		"id",
+		"name",
+		"health.value",
+		"homeSP.id",
+		"currentSP.id",
+		"isReplicationDestination",
+		"cifsServer.id",
+		"nfsServer.id",
+		"nfsServer.nfsv3Enabled",
+		"nfsServer.nfsv4Enabled",
+	}
+	_m.ifaceOpts = api.NewUnityActionOptions(string(api.UnityFileInterface))
+	_m.ifaceOpts.Fields = []string{
+		"id",
+		"name",
+		"nasServer.id",
+		"ipPort.id",
+		"ipAddress",
+		"vlanId",
+	}
+}
+
+func (_m *ModuleNasServer) SetConfig(inf interface{}) Module {
+	data, _ := json.Marshal(inf)
+	json.NewDecoder(bytes.NewReader(data)).Decode(&_m)
+	return _m
+}
+
+func (_m *ModuleNasServer) IsEnabled() bool {
+	return isEnabled(_m.Enabled, _m.defaults)
+}
+
+func (_m *ModuleNasServer) Run(logger *slog.Logger, col *Collector) {
+	meter := col.MeterProvider.Meter(_m.name)
+	client := col.Client
+
+	// Register Metrics...
+	var observableMap map[string]metric.Float64Observable
+	observableMap = CreateMapMetricDescriptor(meter, _m.desc, logger)
+
+	// Register Metrics for Observables...
+	var observableArray []metric.Observable
+	for _, obserable := range observableMap {
+		observableArray = append(observableArray, obserable)
+	}
+
+	// Callback
+	meter.RegisterCallback(func(ctx context.Context, observer metric.Observer) error {
+
+		// Set Attributes
+		if col.detectLabels == nil {
+			return nil
+		}
+		clientAttrs := metric.WithAttributes(append(col.customLabels, col.detectLabels...)...)
+
+		// Request Data
+		data, err := client.GetInstancesContext(ctx, _m.opts)
+		if err != nil {
+			col.handleError(logger, _m.name, err)
+			return nil
+		}
+		col.success = true
+
+		// NAS Servers...
+		for _, v := range data {
+			nasAttrs := metric.WithAttributes(
+				attribute.String("nasServer.id", v.Get("id").String()),
+				attribute.String("nasServer.name", v.Get("name").String()),
+			)
+			homeSP := v.Get("homeSP.id").String()
+			currentSP := v.Get("currentSP.id").String()
+			for _, desc := range _m.desc {
+				key := desc.Key
+				var f float64
+				switch key {
+				case "info":
+					infoAttrs := metric.WithAttributes(
+						attribute.String("sp.home", homeSP),
+						attribute.String("sp.current", currentSP),
+					)
+					observer.ObserveFloat64(observableMap[key], 1, clientAttrs, nasAttrs, infoAttrs)
+					continue
+				case "failover":
+					if currentSP != "" && currentSP != homeSP {
+						f = 1
+					}
+				case "isReplicationDestination":
+					if v.Get(key).Bool() {
+						f = 1
+					}
+				case "cifsServer":
+					if len(v.Get("cifsServer").Array()) > 0 {
+						f = 1
+					}
+				case "nfsServer":
+					if v.Get("nfsServer.nfsv3Enabled").Bool() || v.Get("nfsServer.nfsv4Enabled").Bool() {
+						f = 1
+					}
+				case "interface.info":
+					continue
+				default:
+					f = v.Get(key).Float()
+				}
+				observer.ObserveFloat64(observableMap[key], f, clientAttrs, nasAttrs)
+			}
+		}
+
+		// File Interfaces...
+		ifaces, err := client.GetInstancesContext(ctx, _m.ifaceOpts)
+		if err != nil {
+			col.handleError(logger, _m.name, err)
+			return nil
+		}
+		for _, v := range ifaces {
+			ifaceAttrs := metric.WithAttributes(
+				attribute.String("nasServer.id", v.Get("nasServer.id").String()),
+				attribute.String("interface.id", v.Get("id").String()),
+				attribute.String("interface.name", v.Get("name").String()),
+				attribute.String("interface.ip", v.Get("ipAddress").String()),
+				attribute.String("interface.vlan", v.Get("vlanId").String()),
+				attribute.String("fePort", v.Get("ipPort.id").String()),
+			)
+			observer.ObserveFloat64(observableMap["interface.info"], 1, clientAttrs, ifaceAttrs)
+		}
+
+		return nil
+	}, observableArray...)
+
+}
@@ -0,0 +1,248 @@
+package collectors
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"unisphere_otel_provider/gounity/api"
+	"unisphere_otel_provider/utils"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+func init() {
+	key := "pool"
+	registerModule(key, func() Module { return NewPool() })
+}
+
+type ModulePool struct {
+	// Module's Information
+	name     string
+	opts     *api.UnityActionOptions
+	desc     []*MetricDescriptor
+	defaults bool
+
+	// Configuration File
+	Enabled *bool `yaml:"enabled"`
+}
+
+func NewPool() *ModulePool {
+	return &ModulePool{
+		defaults: false,
+	}
+}
+
+func (_m *ModulePool) Init(key string) {
+	_m.name = key
+	_m.desc = []*MetricDescriptor{
+		{
+			Key:      "health.value",
+			Name:     "unisphere_pool_health",
+			Desc:     "Health of the associated resource",
+			Unit:     "",
+			TypeName: "gauge",
+		},
+		{
+			Key:      "sizeTotal",
+			Name:     "unisphere_pool_total_size",
+			Desc:     "Total Size of the pool",
+			Unit:     "mb",
+			TypeName: "gauge",
+		},
+		{
+			Key:      "sizeUsed",
+			Name:     "unisphere_pool_used_size",
+			Desc:     "Used Size of the pool",
+			Unit:     "mb",
+			TypeName: "gauge",
+		},
+		{
+			Key:      "sizeFree",
+			Name:     "unisphere_pool_free_size",
+			Desc:     "Free Size of the pool",
+			Unit:     "mb",
+			TypeName: "gauge",
+		},
+		{
+			Key:      "sizeSubscribed",
+			Name:     "unisphere_pool_subscribed_size",
+			Desc:     "Size of space requested by the storage resources allocated in the pool for possible future allocations",
+			Unit:     "mb",
+			TypeName: "gauge",
+		},
+		{
+			Key:      "subscriptionPercent",
+			Name:     "unisphere_pool_subscription_percent",
+			Desc:     "Percentage of subscribed size to total size of the pool",
+			Unit:     "%",
+			TypeName: "gauge",
+		},
+		{
+			Key:      "alertThreshold",
+			Name:     "unisphere_pool_alert_threshold",
+			Desc:     "Threshold at which the system will generate alerts about the free space in the pool",
+			Unit:     "%",
+			TypeName: "gauge",
+		},
+		{
+			Key:      "dataReductionRatio",
+			Name:     "unisphere_pool_data_reduction_ratio",
+			Desc:     "Data reduction ratio of the pool",
+			Unit:     "",
+			TypeName: "gauge",
+		},
+		{
+			Key:      "dataReductionPercent",
+			Name:     "unisphere_pool_data_reduction_percent",
+			Desc:     "Data reduction percentage of the pool",
+			Unit:     "%",
+			TypeName: "gauge",
+		},
+		{
+			Key:      "dataReductionSizeSaved",
+			Name:     "unisphere_pool_data_reduction_saved_size",
+			Desc:     "Size saved by data reduction of the pool",
+			Unit:     "mb",
+			TypeName: "gauge",
+		},
+		{
+			Key:      "isFASTVpScheduleEnabled",
+			Name:     "unisphere_pool_fast_vp_schedule_enabled",
+			Desc:     "Indicates whether to enable scheduled data relocations for the pool",
+			Unit:     "",
+			TypeName: "gauge",
+		},
+		{
+			Key:      "poolFastVP.status",
+			Name:     "unisphere_pool_fast_vp_status",
+			Desc:     "FAST VP data relocation status of the pool",
+			Unit:     "",
+			TypeName: "gauge",
+		},
+		// Tier's Metrics...
+		{
+			Key:      "tier.sizeTotal",
+			Name:     "unisphere_pool_tier_total_size",
+			Desc:     "Total Size of the tier in the pool",
+			Unit:     "mb",
+			TypeName: "gauge",
+		},
+		{
+			Key:      "tier.sizeUsed",
+			Name:     "unisphere_pool_tier_used_size",
+			Desc:     "Used Size of the tier in the pool",
+			Unit:     "mb",
+			TypeName: "gauge",
+		},
+		{
+			Key:      "tier.sizeFree",
+			Name:     "unisphere_pool_tier_free_size",
+			Desc:     "Free Size of the tier in the pool",
+			Unit:     "mb",
+			TypeName: "gauge",
+		},
+	}
+	_m.opts = api.NewUnityActionOptions(string(api.UnityPool))
+	_m.opts.Fields = []string{
+		"id",
+		"name",
+		"health.value",
+		"sizeTotal",
+		"sizeUsed",
+		"sizeFree",
+		"sizeSubscribed",
+		"alertThreshold",
+		"dataReductionRatio",
+		"dataReductionPercent",
+		"dataReductionSizeSaved",
+		"isFASTVpScheduleEnabled",
+		"poolFastVP.status",
+		"tiers",
+	}
+}
+
+func (_m *ModulePool) SetConfig(inf interface{}) Module {
+	data, _ := json.Marshal(inf)
+	json.NewDecoder(bytes.NewReader(data)).Decode(&_m)
+	return _m
+}
+
+func (_m *ModulePool) IsEnabled() bool {
+	return isEnabled(_m.Enabled, _m.defaults)
+}
+
+func (_m *ModulePool) Run(logger *slog.Logger, col *Collector) {
+	meter := col.MeterProvider.Meter(_m.name)
+	client := col.Client
+
+	// Register Metrics...
+	var observableMap map[string]metric.Float64Observable
+	observableMap = CreateMapMetricDescriptor(meter, _m.desc, logger)
+
+	// Register Metrics for Observables...
+	var observableArray []metric.Observable
+	for _, obserable := range observableMap {
+		observableArray = append(observableArray, obserable)
+	}
+
+	// Callback
+	meter.RegisterCallback(func(ctx context.Context, observer metric.Observer) error {
+
+		// Set Attributes
+		if col.detectLabels == nil {
+			return nil
+		}
+		clientAttrs := metric.WithAttributes(append(col.customLabels, col.detectLabels...)...)
+
+		// Request Data
+		data, err := client.GetInstancesContext(ctx, _m.opts)
+		if err != nil {
+			col.handleError(logger, _m.name, err)
+			return nil
+		}
+		col.success = true
+
+		for _, v := range data {
+			poolAttrs := metric.WithAttributes(attribute.String("pool.id", v.Get("id").String()), attribute.String("pool.name", v.Get("name").String()))
+			for _, desc := range _m.desc {
+				key := desc.Key
+				switch key {
+				case "sizeTotal", "sizeUsed", "sizeFree", "sizeSubscribed", "dataReductionSizeSaved":
+					observer.ObserveFloat64(observableMap[key], utils.Bytes(v.Get(key).Int()).ToMiB(), clientAttrs, poolAttrs)
+				case "subscriptionPercent":
+					var f float64
+					if v.Get("sizeTotal").Float() > 0 {
+						f = v.Get("sizeSubscribed").Float() / v.Get("sizeTotal").Float() * 100
+					}
+					observer.ObserveFloat64(observableMap[key], f, clientAttrs, poolAttrs)
+				case "isFASTVpScheduleEnabled":
+					var f float64
+					if v.Get(key).Bool() {
+						f = 1
+					}
+					observer.ObserveFloat64(observableMap[key], f, clientAttrs, poolAttrs)
+				case "poolFastVP.status":
+					if v.Get(key).Exists() {
+						observer.ObserveFloat64(observableMap[key], v.Get(key).Float(), clientAttrs, poolAttrs)
+					}
+				case "tier.sizeTotal", "tier.sizeUsed", "tier.sizeFree":
+					// Tier's breakdown...
+					for _, tier := range v.Get("tiers").Array() {
+						if tier.Get("sizeTotal").Int() == 0 {
+							continue
+						}
+						tierAttrs := metric.WithAttributes(attribute.String("tier.name", tier.Get("name").String()))
+						observer.ObserveFloat64(observableMap[key], utils.Bytes(tier.Get(key[len("tier."):]).Int()).ToMiB(), clientAttrs, poolAttrs, tierAttrs)
+					}
+				default:
+					observer.ObserveFloat64(observableMap[key], v.Get(key).Float(), clientAttrs, poolAttrs)
+				}
+			}
+		}
+
+		return nil
+	}, observableArray...)
+
+}
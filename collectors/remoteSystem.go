@@ -0,0 +1,233 @@
+package collectors
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"unisphere_otel_provider/gounity/api"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+func init() {
+	key := "remoteSystem"
+	registerModule(key, func() Module { return NewRemoteSystem() })
+}
+
+// remoteSystemConnectionTypes
+// ReplicationCapabilityEnum of Unisphere
+var remoteSystemConnectionTypes = map[int64]string{
+	0: "Sync",
+	1: "Async",
+	2: "Both",
+}
+
+// replicationNetworkStatuses
+// ReplicationSessionNetworkStatusEnum of Unisphere, which is connected (true) or lost (false).
+var replicationNetworkStatuses = map[int64]bool{
+	1: true,  // OK
+	2: false, // Lost_Communication
+	3: false, // Lost_Sync_Communication
+}
+
+// ModuleRemoteSystem
+// scrape remoteSystem (replication peers) and local replicationInterface.
+type ModuleRemoteSystem struct {
+	// Module's Information
+	name        string
+	opts        *api.UnityActionOptions
+	ifaceOpts   *api.UnityActionOptions
+	sessionOpts *api.UnityActionOptions
+	desc        []*MetricDescriptor
+	defaults    bool
+
+	// Configuration File
+	Enabled *bool `yaml:"enabled"`
+}
+
+func NewRemoteSystem() *ModuleRemoteSystem {
+	return &ModuleRemoteSystem{
+		defaults: false,
+	}
+}
+
+func (_m *ModuleRemoteSystem) Init(key string) {
+	_m.name = key
+	_m.desc = []*MetricDescriptor{
+		{
+			Key:      "info",
+			Name:     "unisphere_remote_system_info",
+			Desc:     "information of the associated resource",
+			Unit:     "",
+			TypeName: "gauge",
+		},
+		{
+			Key:      "health.value",
+			Name:     "unisphere_remote_system_health",
+			Desc:     "Health of the associated resource",
+			Unit:     "",
+			TypeName: "gauge",
+		},
+		{
+			Key:      "connected",
+			Name:     "unisphere_remote_system_connected",
+			Desc:     "Indicates whether replication sessions to the remote system can communicate",
+			Unit:     "",
+			TypeName: "gauge",
+		},
+		// Replication Interface's Metrics...
+		{
+			Key:      "interface.info",
+			Name:     "unisphere_replication_interface_info",
+			Desc:     "information of the replication interface",
+			Unit:     "",
+			TypeName: "gauge",
+		},
+		{
+			Key:      "interface.health",
+			Name:     "unisphere_replication_interface_health",
+			Desc:     "Health of the replication interface",
+			Unit:     "",
+			TypeName: "gauge",
+		},
+	}
+	_m.opts = api.NewUnityActionOptions(string(api.UnityRemoteSystem))
+	_m.opts.Fields = []string{
+		"id",
+		"name",
+		"model",
+		"serialNumber",
+		"managementAddress",
+		"connectionType",
+		"health.value",
+	}
+	_m.ifaceOpts = api.NewUnityActionOptions(string(api.UnityReplicationInterface))
+	_m.ifaceOpts.Fields = []string{
+		"id",
+		"name",
+		"ipPort.id",
+		"ipAddress",
+		"netmask",
+		"gateway",
+		"vlanId",
+		"health.value",
+	}
+	_m.sessionOpts = api.NewUnityActionOptions(string(api.UnityReplicationSession))
+	_m.sessionOpts.Fields = []string{
+		"remoteSystem.id",
+		"networkStatus",
+	}
+}
+
+func (_m *ModuleRemoteSystem) SetConfig(inf interface{}) Module {
+	data, _ := json.Marshal(inf)
+	json.NewDecoder(bytes.NewReader(data)).Decode(&_m)
+	return _m
+}
+
+func (_m *ModuleRemoteSystem) IsEnabled() bool {
+	return isEnabled(_m.Enabled, _m.defaults)
+}
+
+func (_m *ModuleRemoteSystem) Run(logger *slog.Logger, col *Collector) {
+	meter := col.MeterProvider.Meter(_m.name)
+	client := col.Client
+
+	// Register Metrics...
+	var observableMap map[string]metric.Float64Observable
+	observableMap = CreateMapMetricDescriptor(meter, _m.desc, logger)
+
+	// Register Metrics for Observables...
+	var observableArray []metric.Observable
+	for _, obserable := range observableMap {
+		observableArray = append(observableArray, obserable)
+	}
+
+	// Callback
+	meter.RegisterCallback(func(ctx context.Context, observer metric.Observer) error {
+
+		// Set Attributes
+		if col.detectLabels == nil {
+			return nil
+		}
+		clientAttrs := metric.WithAttributes(append(col.customLabels, col.detectLabels...)...)
+
+		// Request Data
+		data, err := client.GetInstancesContext(ctx, _m.opts)
+		if err != nil {
+			col.handleError(logger, _m.name, err)
+			return nil
+		}
+		col.success = true
+
+		// Connection of each remote system, from network status of its replication sessions
+		// lost when any session lost communication, unknown when it has no session.
+		sessions, err := client.GetInstancesContext(ctx, _m.sessionOpts)
+		if err != nil {
+			col.handleError(logger, _m.name, err)
+		}
+		connections := make(map[string]bool)
+		for _, v := range sessions {
+			ok, known := replicationNetworkStatuses[v.Get("networkStatus").Int()]
+			if !known {
+				continue
+			}
+			id := v.Get("remoteSystem.id").String()
+			if prev, seen := connections[id]; seen {
+				ok = ok && prev
+			}
+			connections[id] = ok
+		}
+
+		// Remote Systems...
+		for _, v := range data {
+			remoteAttrs := metric.WithAttributes(
+				attribute.String("remoteSystem.id", v.Get("id").String()),
+				attribute.String("remoteSystem.name", v.Get("name").String()),
+			)
+			infoAttrs := metric.WithAttributes(
+				attribute.String("remoteSystem.model", v.Get("model").String()),
+				attribute.String("remoteSystem.serial", v.Get("serialNumber").String()),
+				attribute.String("remoteSystem.address", v.Get("managementAddress").String()),
+				attribute.String("remoteSystem.connection_type", remoteSystemConnectionTypes[v.Get("connectionType").Int()]),
+			)
+			observer.ObserveFloat64(observableMap["info"], 1, clientAttrs, remoteAttrs, infoAttrs)
+			observer.ObserveFloat64(observableMap["health.value"], v.Get("health.value").Float(), clientAttrs, remoteAttrs)
+
+			if ok, known := connections[v.Get("id").String()]; known {
+				var connected float64
+				if ok {
+					connected = 1
+				}
+				observer.ObserveFloat64(observableMap["connected"], connected, clientAttrs, remoteAttrs)
+			}
+		}
+
+		// Replication Interfaces...
+		ifaces, err := client.GetInstancesContext(ctx, _m.ifaceOpts)
+		if err != nil {
+			col.handleError(logger, _m.name, err)
+			return nil
+		}
+		for _, v := range ifaces {
+			ifaceAttrs := metric.WithAttributes(
+				attribute.String("interface.id", v.Get("id").String()),
+				attribute.String("fePort", v.Get("ipPort.id").String()),
+			)
+			infoAttrs := metric.WithAttributes(
+				attribute.String("interface.name", v.Get("name").String()),
+				attribute.String("interface.ip", v.Get("ipAddress").String()),
+				attribute.String("interface.netmask", v.Get("netmask").String()),
+				attribute.String("interface.gateway", v.Get("gateway").String()),
+				attribute.String("interface.vlan", v.Get("vlanId").String()),
+			)
+			observer.ObserveFloat64(observableMap["interface.info"], 1, clientAttrs, ifaceAttrs, infoAttrs)
+			observer.ObserveFloat64(observableMap["interface.health"], v.Get("health.value").Float(), clientAttrs, ifaceAttrs)
+		}
+
+		return nil
+	}, observableArray...)
+
+}
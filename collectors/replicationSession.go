@@ -0,0 +1,248 @@
+package collectors
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"time"
+	"unisphere_otel_provider/gounity/api"
+	"unisphere_otel_provider/utils"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+func init() {
+	key := "replicationSession"
+	registerModule(key, func() Module { return NewReplicationSession() })
+}
+
+// replicationResourceTypes
+// ReplicationEndpointResourceTypeEnum of Unisphere
+var replicationResourceTypes = map[int64]string{
+	0:     "filesystem",
+	1:     "consistencyGroup",
+	2:     "vmwareFS",
+	3:     "vmwareISCSI",
+	8:     "lun",
+	10000: "nasServer",
+}
+
+// replicationInSyncStates
+// ReplicationSessionSyncStateEnum of Unisphere, which the destination is current.
+var replicationInSyncStates = map[int64]bool{
+	102: true, // In_Sync
+	103: true, // Consistent
+}
+
+type ModuleReplicationSession struct {
+	// Module's Information
+	name     string
+	opts     *api.UnityActionOptions
+	desc     []*MetricDescriptor
+	defaults bool
+
+	// Configuration File
+	Enabled *bool `yaml:"enabled"`
+}
+
+func NewReplicationSession() *ModuleReplicationSession {
+	return &ModuleReplicationSession{
+		defaults: false,
+	}
+}
+
+func (_m *ModuleReplicationSession) Init(key string) {
+	_m.name = key
+	_m.desc = []*MetricDescriptor{
+		{
+			Key:      "info",
+			Name:     "unisphere_replication_session_info",
+			Desc:     "information of the associated resource",
+			Unit:     "",
+			TypeName: "gauge",
+		},
+		{
+			Key:      "health.value",
+			Name:     "unisphere_replication_session_health",
+			Desc:     "Health of the associated resource",
+			Unit:     "",
+			TypeName: "gauge",
+		},
+		{
+			Key:      "syncState",
+			Name:     "unisphere_replication_session_sync_state",
+			Desc:     "Synchronization state of the replication session",
+			Unit:     "",
+			TypeName: "gauge",
+		},
+		{
+			Key:      "networkStatus",
+			Name:     "unisphere_replication_session_network_status",
+			Desc:     "Network status of the replication session",
+			Unit:     "",
+			TypeName: "gauge",
+		},
+		{
+			Key:      "currentTransferEstRemainTime",
+			Name:     "unisphere_replication_session_transfer_remaining_time",
+			Desc:     "Estimated time to complete the current data transfer",
+			Unit:     "s",
+			TypeName: "gauge",
+		},
+		{
+			Key:      "syncProgress",
+			Name:     "unisphere_replication_session_sync_progress",
+			Desc:     "Progress of the current synchronization",
+			Unit:     "%",
+			TypeName: "gauge",
+		},
+		{
+			Key:      "lastSyncTime",
+			Name:     "unisphere_replication_session_last_sync_age",
+			Desc:     "Time elapsed since the last successful synchronization",
+			Unit:     "s",
+			TypeName: "gauge",
+		},
+		{
+			Key:      "maxTimeOutOfSync",
+			Name:     "unisphere_replication_session_rpo",
+			Desc:     "Maximum time to wait before the system syncs the source and destination (RPO)",
+			Unit:     "s",
+			TypeName: "gauge",
+		},
+		{
+			Key:      "current",
+			Name:     "unisphere_replication_session_current",
+			Desc:     "Indicates whether the destination is current, in sync or synchronized within the RPO",
+			Unit:     "",
+			TypeName: "gauge",
+		},
+	}
+	_m.opts = api.NewUnityActionOptions(string(api.UnityReplicationSession))
+	_m.opts.Fields = []string{
+		"id",
+		"name",
+		"replicationResourceType",
+		"localRole",
+		"srcResourceId",
+		"dstResourceId",
+		"remoteSystem.id",
+		"health.value",
+		"syncState",
+		"networkStatus",
+		"currentTransferEstRemainTime",
+		"syncProgress",
+		"lastSyncTime",
+		"maxTimeOutOfSync",
+	}
+}
+
+func (_m *ModuleReplicationSession) SetConfig(inf interface{}) Module {
+	data, _ := json.Marshal(inf)
+	json.NewDecoder(bytes.NewReader(data)).Decode(&_m)
+	return _m
+}
+
+func (_m *ModuleReplicationSession) IsEnabled() bool {
+	return isEnabled(_m.Enabled, _m.defaults)
+}
+
+func (_m *ModuleReplicationSession) Run(logger *slog.Logger, col *Collector) {
+	meter := col.MeterProvider.Meter(_m.name)
+	client := col.Client
+
+	// Register Metrics...
+	var observableMap map[string]metric.Float64Observable
+	observableMap = CreateMapMetricDescriptor(meter, _m.desc, logger)
+
+	// Register Metrics for Observables...
+	var observableArray []metric.Observable
+	for _, obserable := range observableMap {
+		observableArray = append(observableArray, obserable)
+	}
+
+	// Callback
+	meter.RegisterCallback(func(ctx context.Context, observer metric.Observer) error {
+
+		// Set Attributes
+		if col.detectLabels == nil {
+			return nil
+		}
+		clientAttrs := metric.WithAttributes(append(col.customLabels, col.detectLabels...)...)
+
+		// Request Data
+		data, err := client.GetInstancesContext(ctx, _m.opts)
+		if err != nil {
+			col.handleError(logger, _m.name, err)
+			return nil
+		}
+		col.success = true
+
+		now := time.Now()
+		for _, v := range data {
+			sessionAttrs := metric.WithAttributes(
+				attribute.String("replication.id", v.Get("id").String()),
+				attribute.String("srcResource.id", v.Get("srcResourceId").String()),
+				attribute.String("dstResource.id", v.Get("dstResourceId").String()),
+				attribute.String("remoteSystem.id", v.Get("remoteSystem.id").String()),
+			)
+
+			// RPO in minutes, -1 means manual sync, 0 means synchronous replication
+			rpo := v.Get("maxTimeOutOfSync").Int() * 60
+			var lastSyncAge float64
+			lastSync := v.Get("lastSyncTime").Time()
+			if !lastSync.IsZero() {
+				lastSyncAge = now.Sub(lastSync).Seconds()
+			}
+
+			for _, desc := range _m.desc {
+				key := desc.Key
+				var f float64
+				switch key {
+				case "info":
+					infoAttrs := metric.WithAttributes(
+						attribute.String("replication.name", v.Get("name").String()),
+						attribute.String("replication.type", replicationResourceTypes[v.Get("replicationResourceType").Int()]),
+						attribute.String("replication.role", v.Get("localRole").String()),
+					)
+					observer.ObserveFloat64(observableMap[key], 1, clientAttrs, sessionAttrs, infoAttrs)
+					continue
+				case "currentTransferEstRemainTime":
+					// Time interval of "HH:MM:SS.sss"
+					remain, ok := utils.ParseDuration(v.Get(key))
+					if !ok {
+						continue
+					}
+					f = remain.Seconds()
+				case "syncProgress":
+					if !v.Get(key).Exists() {
+						continue
+					}
+					f = v.Get(key).Float()
+				case "lastSyncTime":
+					if lastSync.IsZero() {
+						continue
+					}
+					f = lastSyncAge
+				case "maxTimeOutOfSync":
+					if rpo < 0 {
+						continue
+					}
+					f = float64(rpo)
+				case "current":
+					if replicationInSyncStates[v.Get("syncState").Int()] || (rpo > 0 && !lastSync.IsZero() && lastSyncAge <= float64(rpo)) {
+						f = 1
+					}
+				default:
+					f = v.Get(key).Float()
+				}
+				observer.ObserveFloat64(observableMap[key], f, clientAttrs, sessionAttrs)
+			}
+		}
+
+		return nil
+	}, observableArray...)
+
+}
@@ -0,0 +1,207 @@
+package collectors
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"time"
+	"unisphere_otel_provider/gounity/api"
+	"unisphere_otel_provider/utils"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+func init() {
+	key := "snapshot"
+	registerModule(key, func() Module { return NewSnapshot() })
+}
+
+// ModuleSnapshot
+// scrape snap instances, and aggregate them per storage resource.
+type ModuleSnapshot struct {
+	// Module's Information
+	name     string
+	opts     *api.UnityActionOptions
+	desc     []*MetricDescriptor
+	defaults bool
+
+	// Configuration File
+	Enabled *bool `yaml:"enabled"`
+}
+
+// snapshotSummary
+// snapshots of a storage resource.
+type snapshotSummary struct {
+	name       string
+	count      int64
+	size       int64
+	oldest     time.Time
+	newest     time.Time
+	expiration int64
+	autoDelete int64
+}
+
+func NewSnapshot() *ModuleSnapshot {
+	return &ModuleSnapshot{
+		defaults: false,
+	}
+}
+
+func (_m *ModuleSnapshot) Init(key string) {
+	_m.name = key
+	_m.desc = []*MetricDescriptor{
+		{
+			Key:      "count",
+			Name:     "unisphere_snapshot_count",
+			Desc:     "Number of snapshots of the storage resource",
+			Unit:     "",
+			TypeName: "gauge",
+		},
+		{
+			Key:      "size",
+			Name:     "unisphere_snapshot_total_size",
+			Desc:     "Total Size of snapshots of the storage resource",
+			Unit:     "mb",
+			TypeName: "gauge",
+		},
+		{
+			Key:      "oldest",
+			Name:     "unisphere_snapshot_oldest_age",
+			Desc:     "Age of the oldest snapshot of the storage resource",
+			Unit:     "s",
+			TypeName: "gauge",
+		},
+		{
+			Key:      "newest",
+			Name:     "unisphere_snapshot_newest_age",
+			Desc:     "Age of the newest snapshot of the storage resource",
+			Unit:     "s",
+			TypeName: "gauge",
+		},
+		{
+			Key:      "expiration",
+			Name:     "unisphere_snapshot_expiration_count",
+			Desc:     "Number of snapshots with expiration time of the storage resource",
+			Unit:     "",
+			TypeName: "gauge",
+		},
+		{
+			Key:      "autoDelete",
+			Name:     "unisphere_snapshot_auto_delete_count",
+			Desc:     "Number of snapshots that can be deleted automatically by the system",
+			Unit:     "",
+			TypeName: "gauge",
+		},
+	}
+	_m.opts = api.NewUnityActionOptions(string(api.UnitySnap))
+	_m.opts.Fields = []string{
+		"id",
+		"storageResource.id",
+		"storageResource.name",
+		"size",
+		"creationTime",
+		"expirationTime",
+		"isAutoDelete",
+	}
+}
+
+func (_m *ModuleSnapshot) SetConfig(inf interface{}) Module {
+	data, _ := json.Marshal(inf)
+	json.NewDecoder(bytes.NewReader(data)).Decode(&_m)
+	return _m
+}
+
+func (_m *ModuleSnapshot) IsEnabled() bool {
+	return isEnabled(_m.Enabled, _m.defaults)
+}
+
+func (_m *ModuleSnapshot) Run(logger *slog.Logger, col *Collector) {
+	meter := col.MeterProvider.Meter(_m.name)
+	client := col.Client
+
+	// Register Metrics...
+	var observableMap map[string]metric.Float64Observable
+	observableMap = CreateMapMetricDescriptor(meter, _m.desc, logger)
+
+	// Register Metrics for Observables...
+	var observableArray []metric.Observable
+	for _, obserable := range observableMap {
+		observableArray = append(observableArray, obserable)
+	}
+
+	// Callback
+	meter.RegisterCallback(func(ctx context.Context, observer metric.Observer) error {
+
+		// Set Attributes
+		if col.detectLabels == nil {
+			return nil
+		}
+		clientAttrs := metric.WithAttributes(append(col.customLabels, col.detectLabels...)...)
+
+		// Request Data
+		data, err := client.GetInstancesContext(ctx, _m.opts)
+		if err != nil {
+			col.handleError(logger, _m.name, err)
+			return nil
+		}
+		col.success = true
+
+		// Aggregate per storage resource...
+		summaries := make(map[string]*snapshotSummary)
+		for _, v := range data {
+			id := v.Get("storageResource.id").String()
+			s := summaries[id]
+			if s == nil {
+				s = &snapshotSummary{name: v.Get("storageResource.name").String()}
+				summaries[id] = s
+			}
+			s.count++
+			s.size += v.Get("size").Int()
+			created := v.Get("creationTime").Time()
+			if s.oldest.IsZero() || created.Before(s.oldest) {
+				s.oldest = created
+			}
+			if created.After(s.newest) {
+				s.newest = created
+			}
+			if v.Get("expirationTime").String() != "" {
+				s.expiration++
+			}
+			if v.Get("isAutoDelete").Bool() {
+				s.autoDelete++
+			}
+		}
+
+		now := time.Now()
+		for id, s := range summaries {
+			resourceAttrs := metric.WithAttributes(
+				attribute.String("storageResource.id", id),
+				attribute.String("storageResource.name", s.name),
+			)
+			for _, desc := range _m.desc {
+				key := desc.Key
+				var f float64
+				switch key {
+				case "count":
+					f = float64(s.count)
+				case "size":
+					f = utils.Bytes(s.size).ToMiB()
+				case "oldest":
+					f = now.Sub(s.oldest).Seconds()
+				case "newest":
+					f = now.Sub(s.newest).Seconds()
+				case "expiration":
+					f = float64(s.expiration)
+				case "autoDelete":
+					f = float64(s.autoDelete)
+				}
+				observer.ObserveFloat64(observableMap[key], f, clientAttrs, resourceAttrs)
+			}
+		}
+
+		return nil
+	}, observableArray...)
+
+}
@@ -0,0 +1,148 @@
+package collectors
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"time"
+)
+
+// StateFile
+// persist cursors of log modules (event, alert) per client and module,
+// to resume polling across restarts. nil StateFile keeps cursors in memory only.
+type StateFile struct {
+	path    string
+	mu      sync.Mutex
+	cursors map[string]Cursor
+}
+
+// Cursor
+// the newest timestamp which is emitted, and ids of the records at the timestamp.
+// records at the same timestamp are filtered by ids, so they are not emitted twice.
+type Cursor struct {
+	Timestamp time.Time `json:"timestamp"`
+	IDs       []string  `json:"ids,omitempty"`
+}
+
+// LoadStateFile
+// load the state file, it is created at the first save when it does not exist.
+func LoadStateFile(path string) (*StateFile, error) {
+	s := &StateFile{
+		path:    path,
+		cursors: make(map[string]Cursor),
+	}
+	content, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return s, nil
+	}
+	if err != nil {
+		return s, err
+	}
+	if err = json.Unmarshal(content, &s.cursors); err != nil {
+		return s, err
+	}
+	return s, nil
+}
+
+func stateKey(instance string, module string) string {
+	return instance + "/" + module
+}
+
+// Cursor
+// return the saved cursor of the module.
+func (_s *StateFile) Cursor(instance string, module string) (Cursor, bool) {
+	if _s == nil {
+		return Cursor{}, false
+	}
+	_s.mu.Lock()
+	defer _s.mu.Unlock()
+	c, ok := _s.cursors[stateKey(instance, module)]
+	return c, ok
+}
+
+// SetCursor
+// save the cursor of the module, and write the state file.
+func (_s *StateFile) SetCursor(instance string, module string, c Cursor) error {
+	return _s.SetCursors(instance, map[string]Cursor{module: c})
+}
+
+// SetCursors
+// save cursors by module at once, and write the state file.
+// the file is replaced by rename, so it is not broken on a crash while writing.
+func (_s *StateFile) SetCursors(instance string, cursors map[string]Cursor) error {
+	if _s == nil || len(cursors) == 0 {
+		return nil
+	}
+	_s.mu.Lock()
+	defer _s.mu.Unlock()
+	for module, c := range cursors {
+		_s.cursors[stateKey(instance, module)] = c
+	}
+
+	content, err := json.MarshalIndent(_s.cursors, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(_s.path), filepath.Base(_s.path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err = tmp.Write(content); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err = tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), _s.path)
+}
+
+// resumeCursor
+// load the saved cursor of the module, bounded by the lookback window.
+// resumed is false when it starts from the lookback window,
+// and dropped is true when the saved cursor is older than the window (records between them are not emitted).
+func (_col *Collector) resumeCursor(module string, lookback time.Duration) (c Cursor, resumed bool, dropped bool) {
+	since := time.Now().Add(-lookback).UTC()
+	c, ok := _col.State.Cursor(_col.Instance, module)
+	if !ok {
+		return Cursor{Timestamp: since}, false, false
+	}
+	if c.Timestamp.Before(since) {
+		return Cursor{Timestamp: since}, false, true
+	}
+	return c, true, false
+}
+
+// saveCursor
+// save the cursor of the module, when it is moved.
+func (_col *Collector) saveCursor(module string, prev Cursor, c Cursor) error {
+	if c.Timestamp.Equal(prev.Timestamp) && slices.Equal(c.IDs, prev.IDs) {
+		return nil
+	}
+	return _col.State.SetCursor(_col.Instance, module, c)
+}
+
+// IsNew
+// the record is newer than the cursor.
+func (_c Cursor) IsNew(timestamp time.Time, id string) bool {
+	if timestamp.Equal(_c.Timestamp) {
+		return !slices.Contains(_c.IDs, id)
+	}
+	return timestamp.After(_c.Timestamp)
+}
+
+// Advance
+// move the cursor to the record, when it is not older than the cursor.
+func (_c Cursor) Advance(timestamp time.Time, id string) Cursor {
+	switch {
+	case timestamp.After(_c.Timestamp):
+		return Cursor{Timestamp: timestamp, IDs: []string{id}}
+	case timestamp.Equal(_c.Timestamp) && !slices.Contains(_c.IDs, id):
+		return Cursor{Timestamp: _c.Timestamp, IDs: append(slices.Clone(_c.IDs), id)}
+	}
+	return _c
+}
@@ -13,7 +13,7 @@ import (
 
 func init() {
 	key := "storageProcessor"
-	registerModule(key, NewStorageProcessor())
+	registerModule(key, func() Module { return NewStorageProcessor() })
 }
 
 type ModuleStorageProcessor struct {
@@ -74,6 +74,10 @@ func (_m *ModuleStorageProcessor) SetConfig(inf interface{}) Module {
 	return _m
 }
 
+func (_m *ModuleStorageProcessor) IsEnabled() bool {
+	return isEnabled(_m.Enabled, _m.defaults)
+}
+
 func (_m *ModuleStorageProcessor) Run(logger *slog.Logger, col *Collector) {
 	meter := col.MeterProvider.Meter(_m.name)
 	client := col.Client
@@ -99,9 +103,9 @@ func (_m *ModuleStorageProcessor) Run(logger *slog.Logger, col *Collector) {
 		clientAttrs := metric.WithAttributes(append(col.customLabels, col.detectLabels...)...)
 
 		// Request Data
-		data, err := client.GetInstances(_m.opts)
+		data, err := client.GetInstancesContext(ctx, _m.opts)
 		if err != nil {
-			logger.Error("Failed to get", "error", err, "module", _m.name)
+			col.handleError(logger, _m.name, err)
 			return nil
 		}
 
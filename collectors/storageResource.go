@@ -0,0 +1,208 @@
+package collectors
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"unisphere_otel_provider/gounity/api"
+	"unisphere_otel_provider/utils"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+func init() {
+	key := "storageResource"
+	registerModule(key, func() Module { return NewStorageResource() })
+}
+
+// storageResourceTypes
+// StorageResourceTypeEnum of Unisphere
+var storageResourceTypes = map[int64]string{
+	1:  "filesystem",
+	2:  "consistencyGroup",
+	3:  "vmwarefs",
+	4:  "vmwareiscsi",
+	8:  "lun",
+	9:  "VVolDatastoreFS",
+	10: "VVolDatastoreISCSI",
+}
+
+type ModuleStorageResource struct {
+	// Module's Information
+	name     string
+	opts     *api.UnityActionOptions
+	desc     []*MetricDescriptor
+	defaults bool
+
+	// Configuration File
+	Enabled *bool `yaml:"enabled"`
+}
+
+func NewStorageResource() *ModuleStorageResource {
+	return &ModuleStorageResource{
+		defaults: false,
+	}
+}
+
+func (_m *ModuleStorageResource) Init(key string) {
+	_m.name = key
+	_m.desc = []*MetricDescriptor{
+		{
+			Key:      "info",
+			Name:     "unisphere_storage_resource_info",
+			Desc:     "information of the associated resource",
+			Unit:     "",
+			TypeName: "gauge",
+		},
+		{
+			Key:      "health.value",
+			Name:     "unisphere_storage_resource_health",
+			Desc:     "Health of the associated resource",
+			Unit:     "",
+			TypeName: "gauge",
+		},
+		{
+			Key:      "sizeTotal",
+			Name:     "unisphere_storage_resource_total_size",
+			Desc:     "Total Size of the storage resource",
+			Unit:     "mb",
+			TypeName: "gauge",
+		},
+		{
+			Key:      "sizeUsed",
+			Name:     "unisphere_storage_resource_used_size",
+			Desc:     "Used Size of the storage resource",
+			Unit:     "mb",
+			TypeName: "gauge",
+		},
+		{
+			Key:      "sizeAllocated",
+			Name:     "unisphere_storage_resource_allocated_size",
+			Desc:     "Size of space actually allocated in the pool for the storage resource",
+			Unit:     "mb",
+			TypeName: "gauge",
+		},
+		{
+			Key:      "isThinEnabled",
+			Name:     "unisphere_storage_resource_thin_enabled",
+			Desc:     "Indicates whether thin provisioning is enabled",
+			Unit:     "",
+			TypeName: "gauge",
+		},
+		{
+			Key:      "dataReductionSizeSaved",
+			Name:     "unisphere_storage_resource_data_reduction_saved_size",
+			Desc:     "Size saved by data reduction of the storage resource",
+			Unit:     "mb",
+			TypeName: "gauge",
+		},
+		{
+			Key:      "dataReductionRatio",
+			Name:     "unisphere_storage_resource_data_reduction_ratio",
+			Desc:     "Data reduction ratio of the storage resource",
+			Unit:     "",
+			TypeName: "gauge",
+		},
+		{
+			Key:      "snapSchedule.id",
+			Name:     "unisphere_storage_resource_snap_schedule",
+			Desc:     "Indicates whether a snapshot schedule is applied to the storage resource",
+			Unit:     "",
+			TypeName: "gauge",
+		},
+	}
+	_m.opts = api.NewUnityActionOptions(string(api.UnityStorageResource))
+	_m.opts.Fields = []string{"id", "name", "type", "pools.id"}
+	for _, v := range _m.desc {
+		if v.Key == "info" {
+			continue
+		}
+		_m.opts.Fields = append(_m.opts.Fields, v.Key)
+	}
+}
+
+func (_m *ModuleStorageResource) SetConfig(inf interface{}) Module {
+	data, _ := json.Marshal(inf)
+	json.NewDecoder(bytes.NewReader(data)).Decode(&_m)
+	return _m
+}
+
+func (_m *ModuleStorageResource) IsEnabled() bool {
+	return isEnabled(_m.Enabled, _m.defaults)
+}
+
+func (_m *ModuleStorageResource) Run(logger *slog.Logger, col *Collector) {
+	meter := col.MeterProvider.Meter(_m.name)
+	client := col.Client
+
+	// Register Metrics...
+	var observableMap map[string]metric.Float64Observable
+	observableMap = CreateMapMetricDescriptor(meter, _m.desc, logger)
+
+	// Register Metrics for Observables...
+	var observableArray []metric.Observable
+	for _, obserable := range observableMap {
+		observableArray = append(observableArray, obserable)
+	}
+
+	// Callback
+	meter.RegisterCallback(func(ctx context.Context, observer metric.Observer) error {
+
+		// Set Attributes
+		if col.detectLabels == nil {
+			return nil
+		}
+		clientAttrs := metric.WithAttributes(append(col.customLabels, col.detectLabels...)...)
+
+		// Request Data
+		data, err := client.GetInstancesContext(ctx, _m.opts)
+		if err != nil {
+			col.handleError(logger, _m.name, err)
+			return nil
+		}
+		col.success = true
+
+		for _, v := range data {
+			resourceAttrs := metric.WithAttributes(
+				attribute.String("storageResource.id", v.Get("id").String()),
+				attribute.String("storageResource.name", v.Get("name").String()),
+			)
+			for _, desc := range _m.desc {
+				key := desc.Key
+				var f float64
+				switch key {
+				case "info":
+					var pools []string
+					for _, p := range v.Get("pools.#.id").Array() {
+						pools = append(pools, p.String())
+					}
+					infoAttrs := metric.WithAttributes(
+						attribute.String("storageResource.type", storageResourceTypes[v.Get("type").Int()]),
+						attribute.String("pool.id", strings.Join(pools, ",")),
+					)
+					observer.ObserveFloat64(observableMap[key], 1, clientAttrs, resourceAttrs, infoAttrs)
+					continue
+				case "sizeTotal", "sizeUsed", "sizeAllocated", "dataReductionSizeSaved":
+					f = utils.Bytes(v.Get(key).Int()).ToMiB()
+				case "isThinEnabled":
+					if v.Get(key).Bool() {
+						f = 1
+					}
+				case "snapSchedule.id":
+					if v.Get(key).String() != "" {
+						f = 1
+					}
+				default:
+					f = v.Get(key).Float()
+				}
+				observer.ObserveFloat64(observableMap[key], f, clientAttrs, resourceAttrs)
+			}
+		}
+
+		return nil
+	}, observableArray...)
+
+}
@@ -2,6 +2,7 @@ package api
 
 import (
 	"errors"
+	"strconv"
 	"strings"
 )
 
@@ -9,25 +10,34 @@ const (
 	UnityAPIPrefix   = "/api"
 	UnityTypesPrefix = UnityAPIPrefix + "/types"
 	UnityInstances   = "/instances"
+	UnityActions     = "/action"
 )
 
 type UnityAction string
 
 const (
-	UnityBasicSystemInfo     UnityAction = "basicSystemInfo"
-	UnityStorageProcessor    UnityAction = "storageProcessor"
-	UnitySystemCapacity      UnityAction = "systemCapacity"
-	UnitySystem              UnityAction = "system"
-	UnityLun                 UnityAction = "lun"
-	UnityPool                UnityAction = "pool"
-	UnityStorageResource     UnityAction = "storageResource"
-	UnityEvent               UnityAction = "event"
-	UnityAlert               UnityAction = "alert"
-	UnityMetric              UnityAction = "metric"
-	UnityMetricRealTimeQuery UnityAction = "metricRealTimeQuery"
-	UnityMetricQueryResult   UnityAction = "metricQueryResult"
-	UnityMetricValue         UnityAction = "metricValue"
-	UnityFilesystem          UnityAction = "filesystem"
+	UnityBasicSystemInfo      UnityAction = "basicSystemInfo"
+	UnityStorageProcessor     UnityAction = "storageProcessor"
+	UnitySystemCapacity       UnityAction = "systemCapacity"
+	UnitySystem               UnityAction = "system"
+	UnityLun                  UnityAction = "lun"
+	UnityPool                 UnityAction = "pool"
+	UnityStorageResource      UnityAction = "storageResource"
+	UnityEvent                UnityAction = "event"
+	UnityAlert                UnityAction = "alert"
+	UnityMetric               UnityAction = "metric"
+	UnityMetricRealTimeQuery  UnityAction = "metricRealTimeQuery"
+	UnityMetricQueryResult    UnityAction = "metricQueryResult"
+	UnityMetricValue          UnityAction = "metricValue"
+	UnityFilesystem           UnityAction = "filesystem"
+	UnityLoginSessionInfo     UnityAction = "loginSessionInfo"
+	UnityNasServer            UnityAction = "nasServer"
+	UnityFileInterface        UnityAction = "fileInterface"
+	UnitySnap                 UnityAction = "snap"
+	UnityReplicationSession   UnityAction = "replicationSession"
+	UnityIscsiPortal          UnityAction = "iscsiPortal"
+	UnityRemoteSystem         UnityAction = "remoteSystem"
+	UnityReplicationInterface UnityAction = "replicationInterface"
 )
 
 func (_action UnityAction) String() string {
@@ -41,6 +51,8 @@ type UnityActionOptions struct {
 	mode    string
 	key     string
 	Compact bool
+	PerPage int // Entries per page, server's default when 0
+	Page    int // Page number (starts at 1), first page when 0
 }
 
 func NewUnityActionOptions(action string) *UnityActionOptions {
@@ -58,6 +70,10 @@ func (_opt *UnityActionOptions) WithName(name string) {
 	_opt.mode = "name"
 	_opt.key = name
 }
+func (_opt *UnityActionOptions) WithAction(name string) {
+	_opt.mode = "action"
+	_opt.key = name
+}
 func (_opt *UnityActionOptions) ParseRaw() (string, error) {
 	var raw string
 	switch _opt.mode {
@@ -67,6 +83,8 @@ func (_opt *UnityActionOptions) ParseRaw() (string, error) {
 		raw = UnityAPIPrefix + UnityInstances + "/" + string(_opt.Action) + "/" + _opt.key
 	case "name":
 		raw = UnityAPIPrefix + UnityInstances + "/" + string(_opt.Action) + "/name:" + _opt.key
+	case "action":
+		raw = UnityTypesPrefix + "/" + string(_opt.Action) + UnityActions + "/" + _opt.key
 	default:
 		return "", errors.New("unsupported action mode")
 	}
@@ -77,6 +95,13 @@ func (_opt *UnityActionOptions) ParseRaw() (string, error) {
 	} else {
 		raw += "compact=false"
 	}
+	// Add Pagination...
+	if _opt.PerPage > 0 {
+		raw += "&per_page=" + strconv.Itoa(_opt.PerPage)
+	}
+	if _opt.Page > 0 {
+		raw += "&page=" + strconv.Itoa(_opt.Page)
+	}
 	// No Have Fields & Filters...
 	if len(_opt.Fields)+len(_opt.Filters) == 0 {
 		return raw, nil
@@ -0,0 +1,114 @@
+package gounity
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"strconv"
+
+	"github.com/tidwall/gjson"
+)
+
+// APIError
+// error response of Unisphere REST API.
+type APIError struct {
+	Status         int                 // HTTP status code of the response
+	ErrorCode      int64               // Unity's error code (error.errorCode)
+	HttpStatusCode int64               // HTTP status code reported by Unity (error.httpStatusCode)
+	Messages       []map[string]string // All locales of messages (error.messages)
+	Path           string              // Requested path
+}
+
+func newAPIError(path string, status int, body []byte) *APIError {
+	apiErr := &APIError{
+		Status:         status,
+		ErrorCode:      gjson.GetBytes(body, "error.errorCode").Int(),
+		HttpStatusCode: gjson.GetBytes(body, "error.httpStatusCode").Int(),
+		Path:           path,
+	}
+	for _, m := range gjson.GetBytes(body, "error.messages").Array() {
+		locales := make(map[string]string)
+		for k, v := range m.Map() {
+			locales[k] = v.String()
+		}
+		apiErr.Messages = append(apiErr.Messages, locales)
+	}
+	return apiErr
+}
+
+// Message
+// return the first message in the locale, or en-US when it is not found.
+func (_e *APIError) Message(locale string) string {
+	for _, m := range _e.Messages {
+		if v, ok := m[locale]; ok {
+			return v
+		}
+	}
+	for _, m := range _e.Messages {
+		if v, ok := m["en-US"]; ok {
+			return v
+		}
+	}
+	return ""
+}
+
+func (_e *APIError) Error() string {
+	msg := "unisphere api error: " + strconv.Itoa(_e.Status) + " " + http.StatusText(_e.Status)
+	if _e.ErrorCode != 0 {
+		msg += " (errorCode " + strconv.FormatInt(_e.ErrorCode, 10) + ")"
+	}
+	msg += ": " + _e.Path
+	if m := _e.Message("en-US"); m != "" {
+		msg += ": " + m
+	}
+	return msg
+}
+
+// IsNotFound
+// the requested resource or the object type is not found,
+// e.g. the object type is not supported on this model.
+func IsNotFound(err error) bool {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.Status == http.StatusNotFound
+	}
+	return false
+}
+
+// IsAuth
+// the request is rejected by authentication or authorization.
+func IsAuth(err error) bool {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.Status == http.StatusUnauthorized || apiErr.Status == http.StatusForbidden
+	}
+	return false
+}
+
+// IsRetryable
+// the error is transient (network failure, timeout, server is busy or unavailable),
+// so the same request may succeed later.
+func IsRetryable(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) {
+		return false
+	}
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		switch apiErr.Status {
+		case http.StatusRequestTimeout,
+			http.StatusTooManyRequests,
+			http.StatusInternalServerError,
+			http.StatusBadGateway,
+			http.StatusServiceUnavailable,
+			http.StatusGatewayTimeout:
+			return true
+		}
+		return false
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
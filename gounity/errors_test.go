@@ -0,0 +1,75 @@
+package gounity
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"testing"
+)
+
+func TestNewAPIError(t *testing.T) {
+	body := []byte(`{"error":{"errorCode":131149829,"httpStatusCode":404,"messages":[{"en-US":"The requested resource does not exist."},{"ja-JP":"not found"}]}}`)
+	err := newAPIError("/api/instances/lun/sv_1", http.StatusNotFound, body)
+
+	if err.ErrorCode != 131149829 || err.HttpStatusCode != 404 {
+		t.Errorf("codes = %d, %d", err.ErrorCode, err.HttpStatusCode)
+	}
+	if got := err.Message("ja-JP"); got != "not found" {
+		t.Errorf("Message(ja-JP) = %q", got)
+	}
+	if got := err.Message("fr-FR"); got != "The requested resource does not exist." {
+		t.Errorf("Message(fr-FR) = %q, want en-US fallback", got)
+	}
+	want := "unisphere api error: 404 Not Found (errorCode 131149829): /api/instances/lun/sv_1: The requested resource does not exist."
+	if got := err.Error(); got != want {
+		t.Errorf("Error() = %q, want %q", got, want)
+	}
+}
+
+func TestErrorClassification(t *testing.T) {
+	apiErr := func(status int) error { return &APIError{Status: status, Path: "/api/types/lun/instances"} }
+
+	tests := []struct {
+		name      string
+		err       error
+		notFound  bool
+		auth      bool
+		retryable bool
+	}{
+		{"nil", nil, false, false, false},
+		{"400", apiErr(http.StatusBadRequest), false, false, false},
+		{"401", apiErr(http.StatusUnauthorized), false, true, false},
+		{"403", apiErr(http.StatusForbidden), false, true, false},
+		{"404", apiErr(http.StatusNotFound), true, false, false},
+		{"404 wrapped", fmt.Errorf("lun: %w", apiErr(http.StatusNotFound)), true, false, false},
+		{"408", apiErr(http.StatusRequestTimeout), false, false, true},
+		{"422", apiErr(http.StatusUnprocessableEntity), false, false, false},
+		{"429", apiErr(http.StatusTooManyRequests), false, false, true},
+		{"500", apiErr(http.StatusInternalServerError), false, false, true},
+		{"501", apiErr(http.StatusNotImplemented), false, false, false},
+		{"502", apiErr(http.StatusBadGateway), false, false, true},
+		{"503", apiErr(http.StatusServiceUnavailable), false, false, true},
+		{"504", apiErr(http.StatusGatewayTimeout), false, false, true},
+		{"deadline", context.DeadlineExceeded, false, false, true},
+		{"deadline wrapped", fmt.Errorf("get: %w", context.DeadlineExceeded), false, false, true},
+		{"canceled", context.Canceled, false, false, false},
+		{"network", &net.OpError{Op: "dial", Err: errors.New("connection refused")}, false, false, true},
+		{"circuit open", ErrCircuitOpen, false, false, false},
+		{"other", errors.New("option is required"), false, false, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsNotFound(tt.err); got != tt.notFound {
+				t.Errorf("IsNotFound = %v, want %v", got, tt.notFound)
+			}
+			if got := IsAuth(tt.err); got != tt.auth {
+				t.Errorf("IsAuth = %v, want %v", got, tt.auth)
+			}
+			if got := IsRetryable(tt.err); got != tt.retryable {
+				t.Errorf("IsRetryable = %v, want %v", got, tt.retryable)
+			}
+		})
+	}
+}
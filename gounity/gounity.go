@@ -7,9 +7,11 @@ import (
 	"encoding/json"
 	"errors"
 	"io"
+	"log/slog"
 	"net"
 	"net/http"
 	"net/http/cookiejar"
+	"sync"
 	"time"
 	"unisphere_otel_provider/gounity/api"
 
@@ -18,13 +20,29 @@ import (
 	"go.opentelemetry.io/otel/sdk/resource"
 )
 
+// DefaultMaxPages
+// safety cap of pages followed by GetInstances.
+const DefaultMaxPages = 100
+
+// DefaultTimeout
+// timeout of each HTTP request to Unisphere.
+const DefaultTimeout = 30 * time.Second
+
 type UnisphereClient struct {
 	endpoint string
 	auth     string
 	token    string
 	res      *resource.Resource
 	logined  bool
+	session  int
+	pageSize int
+	maxPages int
+	timeout  time.Duration
+	retry    RetryPolicy
+	breaker  *circuitBreaker
+	logger   *slog.Logger
 
+	mu     sync.Mutex
 	client *http.Client
 }
 
@@ -38,100 +56,403 @@ func NewTransport(insecure bool) *http.Transport {
 }
 
 func NewUnisphereClient(endpoint string, basicAuth string, tr *http.Transport) *UnisphereClient {
+	jar, _ := cookiejar.New(nil)
 	return &UnisphereClient{
 		endpoint: endpoint,
 		auth:     basicAuth,
 		token:    "",
-		client:   &http.Client{Transport: tr},
+		maxPages: DefaultMaxPages,
+		timeout:  DefaultTimeout,
+		retry:    DefaultRetryPolicy,
+		breaker:  newCircuitBreaker(DefaultBreakerFailures, DefaultBreakerProbeInterval),
+		logger:   slog.Default(),
+		client:   &http.Client{Transport: tr, Jar: jar},
+	}
+}
+
+// SetPagination
+// pageSize is entries per page of GetInstances (server's default when 0),
+// maxPages is the safety cap of pages to follow (unlimited when 0).
+func (_c *UnisphereClient) SetPagination(pageSize int, maxPages int) {
+	_c.pageSize = pageSize
+	_c.maxPages = maxPages
+}
+
+// SetLogger
+// logger of warnings from the client (e.g. truncated pages).
+func (_c *UnisphereClient) SetLogger(logger *slog.Logger) {
+	_c.logger = logger
+}
+
+// SetTimeout
+// timeout of each HTTP request (no timeout when 0).
+func (_c *UnisphereClient) SetTimeout(timeout time.Duration) {
+	_c.timeout = timeout
+}
+
+// SetRetryPolicy
+// retry policy of requests on retryable errors.
+func (_c *UnisphereClient) SetRetryPolicy(policy RetryPolicy) {
+	_c.retry = policy
+}
+
+// SetCircuitBreaker
+// open the circuit breaker after consecutive failures (disabled when 0),
+// and probe the array every probeInterval while it is open.
+func (_c *UnisphereClient) SetCircuitBreaker(failures int, probeInterval time.Duration) {
+	_c.breaker = newCircuitBreaker(failures, probeInterval)
+}
+
+// BreakerState
+// current state of the circuit breaker.
+func (_c *UnisphereClient) BreakerState() BreakerState {
+	return _c.breaker.current()
+}
+
+func (_c *UnisphereClient) withTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if _c.timeout > 0 {
+		return context.WithTimeout(ctx, _c.timeout)
+	}
+	return context.WithCancel(ctx)
+}
+
+// Login
+// create a new login session, the session cookie is kept in the cookie jar
+// and the CSRF token is kept for POST/DELETE requests.
+func (_c *UnisphereClient) Login(ctx context.Context) error {
+	_c.mu.Lock()
+	defer _c.mu.Unlock()
+	return _c.login(ctx)
+}
+
+func (_c *UnisphereClient) login(ctx context.Context) error {
+	var req *http.Request
+	var resp *http.Response
+	var body []byte
+	var path string
+	var err error
+
+	// Drop cookies of the previous session,
+	// with a new client (requests in flight keep the previous one, see httpClient)
+	jar, _ := cookiejar.New(nil)
+	_c.client = &http.Client{Transport: _c.client.Transport, Jar: jar}
+	_c.logined = false
+	_c.token = ""
+
+	opt := api.NewUnityActionOptions(string(api.UnityLoginSessionInfo))
+	if path, err = opt.ParseRaw(); err != nil {
+		return err
+	}
+	ctx, cancel := _c.withTimeout(ctx)
+	defer cancel()
+	if req, err = http.NewRequestWithContext(ctx, "GET", _c.endpoint+path, nil); err != nil {
+		return err
+	}
+	req.Header.Add("Accept", "application/json")
+	req.Header.Add("X-EMC-REST-CLIENT", "true")
+	req.Header.Add("Authorization", "Basic "+_c.auth)
+
+	if resp, err = _c.client.Do(req); err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if body, err = io.ReadAll(resp.Body); err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return newAPIError(path, resp.StatusCode, body)
+	}
+
+	_c.token = resp.Header.Get("EMC-CSRF-TOKEN")
+	_c.logined = true
+	_c.session++
+	return nil
+}
+
+// Logout
+// close the login session, so the session is not left on the array.
+func (_c *UnisphereClient) Logout(ctx context.Context) error {
+	_c.mu.Lock()
+	defer _c.mu.Unlock()
+	if !_c.logined {
+		return nil
+	}
+
+	var req *http.Request
+	var resp *http.Response
+	var body []byte
+	var path string
+	var err error
+
+	opt := api.NewUnityActionOptions(string(api.UnityLoginSessionInfo))
+	opt.WithAction("logout")
+	if path, err = opt.ParseRaw(); err != nil {
+		return err
+	}
+	ctx, cancel := _c.withTimeout(ctx)
+	defer cancel()
+	if req, err = http.NewRequestWithContext(ctx, "POST", _c.endpoint+path, bytes.NewBufferString("{}")); err != nil {
+		return err
 	}
+	req.Header.Add("Accept", "application/json")
+	req.Header.Add("Content-Type", "application/json")
+	req.Header.Add("X-EMC-REST-CLIENT", "true")
+	req.Header.Add("EMC-CSRF-TOKEN", _c.token)
+
+	_c.logined = false
+	_c.token = ""
+	if resp, err = _c.client.Do(req); err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if body, err = io.ReadAll(resp.Body); err != nil {
+		return err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return newAPIError(path, resp.StatusCode, body)
+	}
+	return nil
+}
+
+// prepare
+// login when there is no session, or the CSRF token is required but not issued yet.
+// return the token and the session number of the request.
+func (_c *UnisphereClient) prepare(ctx context.Context, method string) (string, int, error) {
+	_c.mu.Lock()
+	defer _c.mu.Unlock()
+	if !_c.logined || (method != "GET" && _c.token == "") {
+		if err := _c.login(ctx); err != nil {
+			return "", 0, err
+		}
+	}
+	return _c.token, _c.session, nil
 }
 
-func (_c *UnisphereClient) send(req *http.Request) ([]byte, error) {
+// expire
+// mark the session as expired, only once for the same session.
+func (_c *UnisphereClient) expire(session int) {
+	_c.mu.Lock()
+	defer _c.mu.Unlock()
+	if _c.session == session {
+		_c.logined = false
+	}
+}
+
+// request
+// send the request through the circuit breaker,
+// and retry with backoff on retryable errors (see canRetry).
+func (_c *UnisphereClient) request(ctx context.Context, method string, path string, reqBody []byte) ([]byte, error) {
+	var body []byte
+	var err error
+	if err = _c.breaker.allow(); err != nil {
+		return nil, err
+	}
+	for attempt := 1; ; attempt++ {
+		body, err = _c.requestSession(ctx, method, path, reqBody)
+		if err == nil || !canRetry(method, err) || attempt >= _c.retry.Attempts {
+			break
+		}
+		if sleepErr := sleepContext(ctx, _c.retry.backoff(attempt)); sleepErr != nil {
+			err = sleepErr
+			break
+		}
+	}
+	_c.breaker.record(err)
+	return body, err
+}
+
+// requestSession
+// send the request with the login session,
+// when the session is expired (401), login again and retry once.
+func (_c *UnisphereClient) requestSession(ctx context.Context, method string, path string, reqBody []byte) ([]byte, error) {
+	var body []byte
+	var token string
+	var session, status int
+	var err error
+	for retry := 0; retry < 2; retry++ {
+		if token, session, err = _c.prepare(ctx, method); err != nil {
+			return nil, err
+		}
+		body, status, err = _c.send(ctx, method, path, reqBody, token)
+		if err != nil {
+			return nil, err
+		}
+		if status != http.StatusUnauthorized {
+			break
+		}
+		_c.expire(session)
+	}
+	return _c.checkStatus(path, status, body)
+}
+
+func (_c *UnisphereClient) send(ctx context.Context, method string, path string, reqBody []byte, token string) ([]byte, int, error) {
 	// Variables...
+	var req *http.Request
 	var resp *http.Response
 	var body []byte
 	var err error
 
-	// Set Header
-	if _c.client.Jar == nil {
-		_c.client.Jar, _ = cookiejar.New(nil)
+	ctx, cancel := _c.withTimeout(ctx)
+	defer cancel()
+	if req, err = http.NewRequestWithContext(ctx, method, _c.endpoint+path, bytes.NewReader(reqBody)); err != nil {
+		return nil, 0, err
 	}
 
+	// Set Header
 	req.Header.Add("Accept", "application/json")
 	req.Header.Add("X-EMC-REST-CLIENT", "true")
-	switch req.Method {
-	case "GET":
-		req.Header.Add("Authorization", "Basic "+_c.auth)
-	case "POST":
+	if method != "GET" {
 		req.Header.Add("Content-Type", "application/json")
-		req.Header.Add("EMC-CSRF-TOKEN", _c.token)
+		req.Header.Add("EMC-CSRF-TOKEN", token)
 	}
 
 	// Send Request
-	if resp, err = _c.client.Do(req); err != nil {
-		return nil, err
+	if resp, err = _c.httpClient().Do(req); err != nil {
+		return nil, 0, err
 	}
 
 	// Read Body
 	defer resp.Body.Close()
 	if body, err = io.ReadAll(resp.Body); err != nil {
-		return nil, err
-	}
-
-	// Check StatusCode
-	switch resp.StatusCode {
-	case http.StatusUnauthorized:
-		_c.logined = false
-	case http.StatusForbidden:
-		return nil, errors.New("forbidden")
-	case http.StatusNotFound:
-		return nil, errors.New("not found")
-	case http.StatusUnprocessableEntity:
-		message := gjson.GetBytes(body, "error.messages.0.en-US").String()
-		return nil, errors.New(message)
-	case http.StatusInternalServerError:
-		message := gjson.GetBytes(body, "error.messages.0.en-US").String()
-		return nil, errors.New(message)
+		return nil, 0, err
 	}
 
 	// Renew Token
 	if resp.Header.Get("EMC-CSRF-TOKEN") != "" {
+		_c.mu.Lock()
 		_c.token = resp.Header.Get("EMC-CSRF-TOKEN")
-		_c.logined = true
+		_c.mu.Unlock()
 	}
 
+	return body, resp.StatusCode, nil
+}
+
+// httpClient
+// current HTTP client, which is replaced on login.
+func (_c *UnisphereClient) httpClient() *http.Client {
+	_c.mu.Lock()
+	defer _c.mu.Unlock()
+	return _c.client
+}
+
+// checkStatus
+// return *APIError when the status is not 2xx.
+func (_c *UnisphereClient) checkStatus(path string, status int, body []byte) ([]byte, error) {
+	if status < 200 || status >= 300 {
+		return nil, newAPIError(path, status, body)
+	}
 	return body, nil
 }
 
+// GetInstances
+// request all pages of the instances, following the next page link
+// until the last page or the max pages cap, and return merged entries.
 func (_c *UnisphereClient) GetInstances(opt *api.UnityActionOptions) ([]gjson.Result, error) {
+	return _c.GetInstancesContext(context.Background(), opt)
+}
+
+// GetInstancesContext
+// GetInstances with the context, the request is aborted when ctx is done.
+func (_c *UnisphereClient) GetInstancesContext(ctx context.Context, opt *api.UnityActionOptions) ([]gjson.Result, error) {
 	var path string
-	var req *http.Request
 	var body []byte
 	var err error
 	if opt == nil {
 		return nil, errors.New("option is required")
 	}
-	if path, err = opt.ParseRaw(); err != nil {
-		return nil, err
+
+	pageOpt := *opt
+	if pageOpt.PerPage == 0 {
+		pageOpt.PerPage = _c.pageSize
 	}
 
-	if req, err = http.NewRequest("GET", _c.endpoint+path, nil); err != nil {
-		return nil, err
+	var data []gjson.Result
+	for page := 1; ; page++ {
+		if page > 1 {
+			pageOpt.Page = page
+		}
+		if path, err = pageOpt.ParseRaw(); err != nil {
+			return nil, err
+		}
+		if body, err = _c.request(ctx, "GET", path, nil); err != nil {
+			return nil, err
+		}
+		data = append(data, gjson.GetBytes(body, "entries.#.content").Array()...)
+
+		if !hasNextPage(body) {
+			break
+		}
+		if _c.maxPages > 0 && page >= _c.maxPages {
+			_c.logger.Warn("max pages is reached, the rest of instances are dropped", "endpoint", _c.endpoint, "path", path, "pages", page)
+			break
+		}
 	}
 
-	if body, err = _c.send(req); err != nil {
-		return nil, err
+	return data, nil
+}
+
+// hasNextPage
+// check the response has a link to the next page.
+func hasNextPage(body []byte) bool {
+	if gjson.GetBytes(body, `links.#(rel=="next")`).Exists() {
+		return true
 	}
+	return gjson.GetBytes(body, `\@next`).Exists() || gjson.GetBytes(body, "nextPage").Exists()
+}
 
-	var data []gjson.Result
-	data = gjson.GetBytes(body, "entries.#.content").Array()
+// GetInstance
+// request an instance by id or name (see UnityActionOptions.WithId, WithName), and return its content.
+func (_c *UnisphereClient) GetInstance(opt *api.UnityActionOptions) (gjson.Result, error) {
+	return _c.GetInstanceContext(context.Background(), opt)
+}
 
-	return data, nil
+// GetInstanceContext
+// GetInstance with the context, the request is aborted when ctx is done.
+func (_c *UnisphereClient) GetInstanceContext(ctx context.Context, opt *api.UnityActionOptions) (gjson.Result, error) {
+	var path string
+	var body []byte
+	var err error
+	if opt == nil {
+		return gjson.Result{}, errors.New("option is required")
+	}
+	if path, err = opt.ParseRaw(); err != nil {
+		return gjson.Result{}, err
+	}
+	if body, err = _c.request(ctx, "GET", path, nil); err != nil {
+		return gjson.Result{}, err
+	}
+	return gjson.GetBytes(body, "content"), nil
+}
+
+// DeleteInstance
+// delete an instance by id or name (see UnityActionOptions.WithId, WithName).
+func (_c *UnisphereClient) DeleteInstance(opt *api.UnityActionOptions) error {
+	return _c.DeleteInstanceContext(context.Background(), opt)
+}
+
+// DeleteInstanceContext
+// DeleteInstance with the context, the request is aborted when ctx is done.
+func (_c *UnisphereClient) DeleteInstanceContext(ctx context.Context, opt *api.UnityActionOptions) error {
+	var path string
+	var err error
+	if opt == nil {
+		return errors.New("option is required")
+	}
+	if path, err = opt.ParseRaw(); err != nil {
+		return err
+	}
+	_, err = _c.request(ctx, "DELETE", path, nil)
+	return err
 }
 
 func (_c *UnisphereClient) PostMetricRealTimeQuery(opt *api.UnityActionOptions, paths []string, interval time.Duration) (string, error) {
+	return _c.PostMetricRealTimeQueryContext(context.Background(), opt, paths, interval)
+}
+
+// PostMetricRealTimeQueryContext
+// PostMetricRealTimeQuery with the context, the request is aborted when ctx is done.
+func (_c *UnisphereClient) PostMetricRealTimeQueryContext(ctx context.Context, opt *api.UnityActionOptions, paths []string, interval time.Duration) (string, error) {
 	var path string
-	var req *http.Request
 	var body []byte
 	var err error
 	if opt == nil {
@@ -148,12 +469,11 @@ func (_c *UnisphereClient) PostMetricRealTimeQuery(opt *api.UnityActionOptions,
 	reqData.Paths = paths
 	reqData.Interval = int(interval / time.Second)
 	reqBody, err := json.Marshal(reqData)
-
-	if req, err = http.NewRequest("POST", _c.endpoint+path, bytes.NewBuffer(reqBody)); err != nil {
+	if err != nil {
 		return "", err
 	}
 
-	if body, err = _c.send(req); err != nil {
+	if body, err = _c.request(ctx, "POST", path, reqBody); err != nil {
 		return "", err
 	}
 
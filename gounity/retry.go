@@ -0,0 +1,167 @@
+package gounity
+
+import (
+	"context"
+	"errors"
+	"math/rand/v2"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen
+// the request is short-circuited, because the array is considered down.
+var ErrCircuitOpen = errors.New("circuit breaker is open")
+
+// RetryPolicy
+// retry of a request on retryable errors (see IsRetryable).
+type RetryPolicy struct {
+	Attempts       int           // Total attempts including the first request, no retry when <= 1
+	InitialBackoff time.Duration // Backoff before the first retry, doubled on each retry
+	MaxBackoff     time.Duration // Upper limit of backoff, unlimited when 0
+}
+
+// DefaultRetryPolicy
+// default retry policy of UnisphereClient.
+var DefaultRetryPolicy = RetryPolicy{
+	Attempts:       3,
+	InitialBackoff: 500 * time.Millisecond,
+	MaxBackoff:     5 * time.Second,
+}
+
+// canRetry
+// GET is retried on any retryable error. other methods (POST, DELETE) may be processed by the array
+// even when the response is lost (timeout, network error), so they are retried only when
+// the array rejected them without processing (busy or unavailable).
+func canRetry(method string, err error) bool {
+	if method == "GET" {
+		return IsRetryable(err)
+	}
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.Status == http.StatusTooManyRequests || apiErr.Status == http.StatusServiceUnavailable
+	}
+	return false
+}
+
+// backoff
+// exponential backoff with jitter, between half and full of the backoff.
+func (_p RetryPolicy) backoff(retry int) time.Duration {
+	d := _p.InitialBackoff
+	for i := 1; i < retry && (_p.MaxBackoff <= 0 || d < _p.MaxBackoff); i++ {
+		d *= 2
+	}
+	if _p.MaxBackoff > 0 && d > _p.MaxBackoff {
+		d = _p.MaxBackoff
+	}
+	if d <= 0 {
+		return 0
+	}
+	return d/2 + rand.N(d/2+1)
+}
+
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+type BreakerState int
+
+const (
+	BreakerClosed BreakerState = iota
+	BreakerHalfOpen
+	BreakerOpen
+)
+
+var breakerStates = map[BreakerState]string{
+	BreakerClosed:   "closed",
+	BreakerHalfOpen: "half-open",
+	BreakerOpen:     "open",
+}
+
+func (_s BreakerState) String() string {
+	return breakerStates[_s]
+}
+
+// DefaultBreakerFailures
+// consecutive failures to open the circuit breaker.
+const DefaultBreakerFailures = 5
+
+// DefaultBreakerProbeInterval
+// interval to probe the array while the circuit breaker is open.
+const DefaultBreakerProbeInterval = 30 * time.Second
+
+// circuitBreaker
+// open after consecutive retryable failures, and short-circuit requests.
+// when the probe interval is passed, one request is allowed to probe the array (half-open),
+// the breaker is closed when the probe succeeds, and opened again when it fails.
+type circuitBreaker struct {
+	failures      int // Threshold, disabled when 0
+	probeInterval time.Duration
+
+	mu       sync.Mutex
+	state    BreakerState
+	count    int
+	openedAt time.Time
+}
+
+func newCircuitBreaker(failures int, probeInterval time.Duration) *circuitBreaker {
+	return &circuitBreaker{
+		failures:      failures,
+		probeInterval: probeInterval,
+	}
+}
+
+func (_b *circuitBreaker) allow() error {
+	_b.mu.Lock()
+	defer _b.mu.Unlock()
+	switch _b.state {
+	case BreakerOpen:
+		if time.Since(_b.openedAt) < _b.probeInterval {
+			return ErrCircuitOpen
+		}
+		_b.state = BreakerHalfOpen
+		return nil
+	case BreakerHalfOpen:
+		return ErrCircuitOpen
+	}
+	return nil
+}
+
+func (_b *circuitBreaker) record(err error) {
+	_b.mu.Lock()
+	defer _b.mu.Unlock()
+
+	// Canceled by the caller, the result is unknown.
+	if errors.Is(err, context.Canceled) {
+		if _b.state == BreakerHalfOpen {
+			_b.state = BreakerOpen
+		}
+		return
+	}
+
+	// The array responded, even if it is an error.
+	if !IsRetryable(err) {
+		_b.state = BreakerClosed
+		_b.count = 0
+		return
+	}
+
+	_b.count++
+	if _b.state == BreakerHalfOpen || (_b.failures > 0 && _b.count >= _b.failures) {
+		_b.state = BreakerOpen
+		_b.openedAt = time.Now()
+	}
+}
+
+func (_b *circuitBreaker) current() BreakerState {
+	_b.mu.Lock()
+	defer _b.mu.Unlock()
+	return _b.state
+}
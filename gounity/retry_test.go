@@ -0,0 +1,178 @@
+package gounity
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestRetryPolicyBackoff(t *testing.T) {
+	policy := RetryPolicy{Attempts: 5, InitialBackoff: 100 * time.Millisecond, MaxBackoff: time.Second}
+
+	tests := []struct {
+		name   string
+		policy RetryPolicy
+		retry  int
+		want   time.Duration // Backoff before jitter, the result is between half and full of it
+	}{
+		{"first", policy, 1, 100 * time.Millisecond},
+		{"second", policy, 2, 200 * time.Millisecond},
+		{"third", policy, 3, 400 * time.Millisecond},
+		{"fourth", policy, 4, 800 * time.Millisecond},
+		{"capped", policy, 5, time.Second},
+		{"capped far", policy, 100, time.Second},
+		{"no max", RetryPolicy{InitialBackoff: 100 * time.Millisecond}, 3, 400 * time.Millisecond},
+		{"no backoff", RetryPolicy{}, 3, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for i := 0; i < 100; i++ {
+				got := tt.policy.backoff(tt.retry)
+				if got < tt.want/2 || got > tt.want {
+					t.Fatalf("backoff(%d) = %v, want between %v and %v", tt.retry, got, tt.want/2, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestCanRetry(t *testing.T) {
+	network := &net.OpError{Op: "read", Err: errors.New("connection reset by peer")}
+
+	tests := []struct {
+		name   string
+		method string
+		err    error
+		want   bool
+	}{
+		{"GET ok", "GET", nil, false},
+		{"GET 404", "GET", &APIError{Status: http.StatusNotFound}, false},
+		{"GET 500", "GET", &APIError{Status: http.StatusInternalServerError}, true},
+		{"GET 503", "GET", &APIError{Status: http.StatusServiceUnavailable}, true},
+		{"GET timeout", "GET", context.DeadlineExceeded, true},
+		{"GET network", "GET", network, true},
+		{"GET canceled", "GET", context.Canceled, false},
+		{"POST 429", "POST", &APIError{Status: http.StatusTooManyRequests}, true},
+		{"POST 503", "POST", &APIError{Status: http.StatusServiceUnavailable}, true},
+		{"POST 500", "POST", &APIError{Status: http.StatusInternalServerError}, false},
+		{"POST 504", "POST", &APIError{Status: http.StatusGatewayTimeout}, false},
+		{"POST timeout", "POST", context.DeadlineExceeded, false},
+		{"POST network", "POST", network, false},
+		{"DELETE 503", "DELETE", &APIError{Status: http.StatusServiceUnavailable}, true},
+		{"DELETE timeout", "DELETE", context.DeadlineExceeded, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := canRetry(tt.method, tt.err); got != tt.want {
+				t.Errorf("canRetry(%s, %v) = %v, want %v", tt.method, tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCircuitBreaker(t *testing.T) {
+	transient := &APIError{Status: http.StatusServiceUnavailable}
+	notFound := &APIError{Status: http.StatusNotFound}
+
+	// step is a request through the breaker:
+	// allow is called, and the result is recorded when it is allowed.
+	type step struct {
+		probe   bool  // The probe interval is passed before the request
+		err     error // Result of the request
+		allowed bool
+		state   BreakerState // State after the request
+	}
+	tests := []struct {
+		name     string
+		failures int
+		steps    []step
+	}{
+		{"closed on success", 3, []step{
+			{err: nil, allowed: true, state: BreakerClosed},
+			{err: transient, allowed: true, state: BreakerClosed},
+			{err: nil, allowed: true, state: BreakerClosed},
+		}},
+		{"open after consecutive failures", 3, []step{
+			{err: transient, allowed: true, state: BreakerClosed},
+			{err: transient, allowed: true, state: BreakerClosed},
+			{err: transient, allowed: true, state: BreakerOpen},
+			{err: nil, allowed: false, state: BreakerOpen},
+		}},
+		{"reset by a response", 3, []step{
+			{err: transient, allowed: true, state: BreakerClosed},
+			{err: transient, allowed: true, state: BreakerClosed},
+			{err: notFound, allowed: true, state: BreakerClosed},
+			{err: transient, allowed: true, state: BreakerClosed},
+			{err: transient, allowed: true, state: BreakerClosed},
+		}},
+		{"closed by probe", 1, []step{
+			{err: transient, allowed: true, state: BreakerOpen},
+			{probe: true, err: nil, allowed: true, state: BreakerClosed},
+			{err: transient, allowed: true, state: BreakerOpen},
+		}},
+		{"closed by probe with error response", 1, []step{
+			{err: transient, allowed: true, state: BreakerOpen},
+			{probe: true, err: notFound, allowed: true, state: BreakerClosed},
+		}},
+		{"open again by failed probe", 1, []step{
+			{err: transient, allowed: true, state: BreakerOpen},
+			{probe: true, err: transient, allowed: true, state: BreakerOpen},
+			{err: nil, allowed: false, state: BreakerOpen},
+		}},
+		{"open again by canceled probe", 1, []step{
+			{err: transient, allowed: true, state: BreakerOpen},
+			{probe: true, err: context.Canceled, allowed: true, state: BreakerOpen},
+		}},
+		{"canceled is not a failure", 1, []step{
+			{err: context.Canceled, allowed: true, state: BreakerClosed},
+		}},
+		{"disabled", 0, []step{
+			{err: transient, allowed: true, state: BreakerClosed},
+			{err: transient, allowed: true, state: BreakerClosed},
+			{err: transient, allowed: true, state: BreakerClosed},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := newCircuitBreaker(tt.failures, time.Hour)
+			for i, s := range tt.steps {
+				if s.probe {
+					b.openedAt = time.Now().Add(-2 * time.Hour)
+				}
+				err := b.allow()
+				if allowed := err == nil; allowed != s.allowed {
+					t.Fatalf("step %d: allowed = %v, want %v", i, allowed, s.allowed)
+				}
+				if err != nil && !errors.Is(err, ErrCircuitOpen) {
+					t.Fatalf("step %d: allow() = %v", i, err)
+				}
+				if err == nil {
+					b.record(s.err)
+				}
+				if got := b.current(); got != s.state {
+					t.Fatalf("step %d: state = %v, want %v", i, got, s.state)
+				}
+			}
+		})
+	}
+}
+
+func TestCircuitBreakerHalfOpen(t *testing.T) {
+	b := newCircuitBreaker(1, time.Hour)
+	b.record(&APIError{Status: http.StatusServiceUnavailable})
+	b.openedAt = time.Now().Add(-2 * time.Hour)
+
+	// Only one request probes the array
+	if err := b.allow(); err != nil {
+		t.Fatalf("probe: allow() = %v", err)
+	}
+	if got := b.current(); got != BreakerHalfOpen {
+		t.Fatalf("state = %v, want %v", got, BreakerHalfOpen)
+	}
+	if err := b.allow(); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("while probing: allow() = %v, want %v", err, ErrCircuitOpen)
+	}
+}
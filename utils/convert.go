@@ -1,6 +1,12 @@
 package utils
 
-import "github.com/tidwall/gjson"
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/tidwall/gjson"
+)
 
 type Bytes int64
 
@@ -48,3 +54,33 @@ func parseMetric(result []*Metric, labels []string, data gjson.Result) ([]*Metri
 	}
 	return result, nil, gjson.Result{}
 }
+
+// ParseDuration
+// parse a time interval of Unisphere, which is a string "HH:MM:SS.sss" (hours may exceed 24),
+// or a number of seconds. ok is false when it does not exist or is not a duration.
+func ParseDuration(data gjson.Result) (time.Duration, bool) {
+	switch data.Type {
+	case gjson.Number:
+		return time.Duration(data.Float() * float64(time.Second)), true
+	case gjson.String:
+	default:
+		return 0, false
+	}
+	parts := strings.Split(data.String(), ":")
+	if len(parts) != 3 {
+		return 0, false
+	}
+	hours, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil || hours < 0 {
+		return 0, false
+	}
+	minutes, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil || minutes < 0 || minutes >= 60 {
+		return 0, false
+	}
+	seconds, err := strconv.ParseFloat(parts[2], 64)
+	if err != nil || seconds < 0 || seconds >= 60 {
+		return 0, false
+	}
+	return time.Duration(hours)*time.Hour + time.Duration(minutes)*time.Minute + time.Duration(seconds*float64(time.Second)), true
+}
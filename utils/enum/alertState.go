@@ -0,0 +1,19 @@
+package enum
+
+type AlertStateEnum int64
+
+const (
+	AlertStateActiveManual AlertStateEnum = iota
+	AlertStateActiveAuto
+	AlertStateInactive
+)
+
+var AlertState = map[AlertStateEnum]string{
+	AlertStateActiveManual: "Active_Manual",
+	AlertStateActiveAuto:   "Active_Auto",
+	AlertStateInactive:     "Inactive",
+}
+
+func (_enum AlertStateEnum) String() string {
+	return AlertState[_enum]
+}
@@ -1,5 +1,7 @@
 package enum
 
+import "go.opentelemetry.io/otel/log"
+
 type SeverityEnum int64
 
 const (
@@ -29,3 +31,24 @@ var Severity = map[SeverityEnum]string{
 func (_enum SeverityEnum) String() string {
 	return Severity[_enum]
 }
+
+// logSeverity
+// Unity's severities follow syslog, mapped to OpenTelemetry's severity numbers
+// as the syslog mapping of OpenTelemetry's log data model.
+var logSeverity = map[SeverityEnum]log.Severity{
+	SeverityEMERGENCY: log.SeverityFatal,
+	SeverityALERT:     log.SeverityError3,
+	SeverityCRITICAL:  log.SeverityError2,
+	SeverityERROR:     log.SeverityError,
+	SeverityWARNING:   log.SeverityWarn,
+	SeverityNOTICE:    log.SeverityInfo2,
+	SeverityINFO:      log.SeverityInfo,
+	SeverityDEBUG:     log.SeverityDebug,
+	SeverityOK:        log.SeverityInfo,
+}
+
+// LogSeverity
+// severity number of OpenTelemetry, undefined for unknown severities.
+func (_enum SeverityEnum) LogSeverity() log.Severity {
+	return logSeverity[_enum]
+}